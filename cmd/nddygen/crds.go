@@ -0,0 +1,59 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nddygen
+
+import (
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-ygen/pkg/generator"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// crdsCmd represents the generate crds command
+var crdsCmd = &cobra.Command{
+	Use:          "crds",
+	Short:        "generate the custom resource definitions of the ndd provider using yang",
+	Aliases:      []string{"crd"},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		zlog := zap.New(zap.UseDevMode(debug), zap.JSONEncoder())
+		log := logging.NewLogrLogger(zlog.WithName("nddgenyang"))
+		log.Debug("generate crds ...")
+
+		g, err := generator.NewGenerator(append(generatorOptions(log), generator.WithOutputFormat(generator.OutputFormatCrds))...)
+		if err != nil {
+			return errors.Wrap(err, errCreateGenerator)
+		}
+		printDiagnostics(g)
+
+		if err := g.Run(); err != nil {
+			log.Debug("Error", "error", err)
+			return err
+		}
+		if err := g.Render(); err != nil {
+			log.Debug("Error", "error", err)
+			return err
+		}
+
+		return checkStaleOutput()
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(crdsCmd)
+}
@@ -17,8 +17,12 @@ limitations under the License.
 package nddygen
 
 import (
-	"github.com/netw-device-driver/ndd-ygen/pkg/generator"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-ygen/pkg/generator"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -32,9 +36,18 @@ var packageName string
 var version string
 var prefix string
 var apiGroup string
+var module string
+var templateDir string
+var outputFormat string
+var yangStrict bool
+var check bool
+
+// checkOutput holds the generated files in memory when the output is checked
+var checkOutput *generator.MemoryOutput
 
 const (
 	errCreateGenerator = "cannot initialize generator"
+	errStaleOutput     = "generated output is stale, regenerate it"
 )
 
 // generateCmd represents the generate command
@@ -48,24 +61,12 @@ var generateCmd = &cobra.Command{
 		log := logging.NewLogrLogger(zlog.WithName("nddgenyang"))
 		log.Debug("generate provider ...")
 
-		opts := []generator.Option{
-			generator.WithYangImportDirs(yangImportDirs),
-			generator.WithYangModuleDirs(yangModuleDirs),
-			generator.WithResourceMapInputFile(resourceMapInputFile),
-			generator.WithOutputDir(outputDir),
-			generator.WithPackageName(packageName),
-			generator.WithVersion(version),
-			generator.WithAPIGroup(apiGroup),
-			generator.WithPrefix(prefix),
-			generator.WithLogging(log),
-			generator.WithDebug(debug),
-			generator.WithLocalRender(true),
-		}
-		g, err := generator.NewGenerator(opts...)
+		g, err := generator.NewGenerator(generatorOptions(log)...)
 		if err != nil {
 			return errors.Wrap(err, errCreateGenerator)
 		}
 		//g.ShowConfiguration()
+		printDiagnostics(g)
 
 		if err := g.Run(); err != nil {
 			log.Debug("Error", "error", err)
@@ -76,19 +77,74 @@ var generateCmd = &cobra.Command{
 			return err
 		}
 
-		return nil
+		return checkStaleOutput()
 	},
 }
 
+// printDiagnostics prints the errors of processing the yang modules which do not fail
+// the generation, together with the resources they affect
+func printDiagnostics(g *generator.Generator) {
+	for _, d := range g.Diagnostics() {
+		fmt.Printf("yang error: %s\n", d)
+	}
+}
+
+// checkStaleOutput prints a diff of the generated files which differ from the files in
+// the output directory, when the output is checked
+func checkStaleOutput() error {
+	if checkOutput == nil {
+		return nil
+	}
+	stale, err := checkOutput.Diff(os.Stdout)
+	if err != nil {
+		return err
+	}
+	if stale {
+		return errors.New(errStaleOutput)
+	}
+	return nil
+}
+
+// generatorOptions returns the generator options from the flags of the generate command
+func generatorOptions(log logging.Logger) []generator.Option {
+	opts := []generator.Option{
+		generator.WithYangImportDirs(yangImportDirs),
+		generator.WithYangModuleDirs(yangModuleDirs),
+		generator.WithResourceMapInputFile(resourceMapInputFile),
+		generator.WithOutputDir(outputDir),
+		generator.WithPackageName(packageName),
+		generator.WithVersion(version),
+		generator.WithAPIGroup(apiGroup),
+		generator.WithPrefix(prefix),
+		generator.WithModule(module),
+		generator.WithOutputFormat(outputFormat),
+		generator.WithLogging(log),
+		generator.WithDebug(debug),
+		generator.WithLocalRender(true),
+		generator.WithTemplateDir(templateDir),
+		generator.WithStrict(yangStrict),
+	}
+	if check {
+		// the files are compared with the output directory instead of written
+		checkOutput = generator.NewMemoryOutput()
+		opts = append(opts, generator.WithOutput(checkOutput))
+	}
+	return opts
+}
+
 func init() {
 	rootCmd.AddCommand(generateCmd)
-	generateCmd.Flags().StringSliceVarP(&yangImportDirs, "yang-import-dirs", "i", []string{"/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/yang/21_03_0/ietf/"}, "Comma separated list of dirs to be recursively searched for import modules.")
-	generateCmd.Flags().StringSliceVarP(&yangModuleDirs, "yang-module-dirs", "m", []string{"/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/yang/21_03_0/srl/"}, "Comma separated list of dirs to be recursively searched for yang modules")
-	generateCmd.Flags().StringVarP(&resourceMapInputFile, "resource-map-input", "r", "/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/resourceMapInputPlayK8s.yaml", "The resource map input file which resource should be generated")
-	generateCmd.Flags().StringVarP(&outputDir, "output-dir", "o", "out/", "The directory that the Go package should be written to.")
-	generateCmd.Flags().StringVarP(&packageName, "package-name", "p", "tfsrl", "The packageName the code will generate")
-	generateCmd.Flags().StringVarP(&version, "version", "v", "v1", "The version of the api to geenrate")
-	generateCmd.Flags().StringVarP(&apiGroup, "apiGroup", "g", "srl.ndd.henderiw.be", "The group of the api to geenrate")
-	generateCmd.Flags().StringVarP(&prefix, "prefix", "a", "srl", "The prefix that is added to the kubernetes api resource")
-	
+	generateCmd.PersistentFlags().StringSliceVarP(&yangImportDirs, "yang-import-dirs", "i", []string{"/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/yang/21_03_0/ietf/"}, "Comma separated list of dirs to be recursively searched for import modules.")
+	generateCmd.PersistentFlags().StringSliceVarP(&yangModuleDirs, "yang-module-dirs", "m", []string{"/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/yang/21_03_0/srl/"}, "Comma separated list of dirs to be recursively searched for yang modules")
+	generateCmd.PersistentFlags().StringVarP(&resourceMapInputFile, "resource-map-input", "r", "/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/resourceMapInputPlayK8s.yaml", "The resource map input file which resource should be generated")
+	generateCmd.PersistentFlags().StringVarP(&outputDir, "output-dir", "o", "out/", "The directory that the Go package should be written to.")
+	generateCmd.PersistentFlags().StringVarP(&packageName, "package-name", "p", "tfsrl", "The packageName the code will generate")
+	generateCmd.PersistentFlags().StringVarP(&version, "version", "v", "v1", "The version of the api to geenrate")
+	generateCmd.PersistentFlags().StringVarP(&apiGroup, "apiGroup", "g", "srl.ndd.henderiw.be", "The group of the api to geenrate")
+	generateCmd.PersistentFlags().StringVarP(&prefix, "prefix", "a", "srl", "The prefix that is added to the kubernetes api resource")
+	generateCmd.PersistentFlags().StringVarP(&module, "module", "", "github.com/netw-device-driver/ndd-provider-srl", "The go module of the provider the code is generated for")
+	generateCmd.Flags().StringVarP(&outputFormat, "output-format", "", generator.OutputFormatK8s, "The output format the resources are rendered in: "+strings.Join(generator.OutputFormats(), ", "))
+	generateCmd.PersistentFlags().BoolVarP(&yangStrict, "yang-strict", "", false, "fail the generation on errors of processing the yang modules instead of reporting the affected resources")
+	generateCmd.PersistentFlags().BoolVarP(&check, "check", "", false, "compare the generated files with the output directory without writing them, a diff is printed and the command fails when they differ")
+	generateCmd.PersistentFlags().StringVarP(&templateDir, "template-dir", "", "", "The directory with templates that replace the default templates with the same name")
 }
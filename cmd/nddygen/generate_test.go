@@ -0,0 +1,138 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nddygen
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// envCommandArgs holds the arguments of the command when the test binary runs the
+// command in a separate process, goyang keeps the merged submodules in global state
+// and hence cannot process the same yang modules twice in a process
+const envCommandArgs = "NDD_YGEN_TEST_COMMAND_ARGS"
+
+// TestCommandProcess runs the command with the arguments in the environment when the
+// test binary is run by runCommand, the process exits with 1 when the command fails
+func TestCommandProcess(t *testing.T) {
+	args := os.Getenv(envCommandArgs)
+	if args == "" {
+		t.Skip("the command is only run in a process started by runCommand")
+	}
+	rootCmd.SetArgs(strings.Split(args, "\n"))
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// runCommand runs the command in a separate process and returns its output and its error
+func runCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCommandProcess$")
+	cmd.Env = append(os.Environ(), envCommandArgs+"="+strings.Join(args, "\n"))
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// generateArgs returns the arguments to generate the docs of the resource map
+func generateArgs(outputDir, resourceMap string, extra ...string) []string {
+	return append([]string{
+		"generate",
+		"-d=false",
+		"-i", "../../conf/yang/21_03_0/ietf",
+		"-m", "../../conf/yang/21_03_0/srl",
+		"-r", resourceMap,
+		"-o", outputDir,
+		"--output-format", "docs",
+	}, extra...)
+}
+
+// readTree returns the content of the files in the directory by their relative path
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		files[rel] = string(b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestGenerateCheck(t *testing.T) {
+	dir := t.TempDir()
+	resourceMap := "../../pkg/generator/testdata/resourcemap.yaml"
+	if out, err := runCommand(t, generateArgs(dir, resourceMap)...); err != nil {
+		t.Fatalf("generate: %v\n%s", err, out)
+	}
+
+	out, err := runCommand(t, generateArgs(dir, resourceMap, "--check")...)
+	if err != nil {
+		t.Fatalf("generate --check of the generated output: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "--- ") {
+		t.Errorf("generate --check of the generated output prints a diff:\n%s", out)
+	}
+
+	// the interface docs are changed and the tunnel interfaces are no longer generated
+	changed := filepath.Join(dir, "docs", "srl-interface.md")
+	b, err := ioutil.ReadFile(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(changed, append(b, []byte("changed\n")...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	smallMap := filepath.Join(t.TempDir(), "resourcemap.yaml")
+	if err := ioutil.WriteFile(smallMap, []byte("path:\n    /srl_nokia-interfaces/interface:\n        hierarchy:\n            /subinterface:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := readTree(t, dir)
+
+	out, err = runCommand(t, generateArgs(dir, smallMap, "--check")...)
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Errorf("generate --check of a stale output: error = %v, want a non-zero exit", err)
+	}
+	removed := filepath.Join(dir, "docs", "srl-tunnelinterface.md")
+	for _, want := range []string{
+		"--- " + changed + "\tcurrent\n+++ " + changed + "\tgenerated\n",
+		"-changed\n",
+		"--- " + removed + "\tcurrent\n+++ " + removed + "\tgenerated\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generate --check of a stale output does not print %q:\n%s", want, out)
+		}
+	}
+	if after := readTree(t, dir); !reflect.DeepEqual(after, before) {
+		t.Errorf("generate --check changed the output directory")
+	}
+}
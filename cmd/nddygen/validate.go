@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nddygen
+
+import (
+	"fmt"
+
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-ygen/pkg/generator"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var strict bool
+
+const (
+	errValidation = "validation failed"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "validate the resource map and the yang modules the ndd provider is generated from",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		zlog := zap.New(zap.UseDevMode(debug), zap.JSONEncoder())
+		log := logging.NewLogrLogger(zlog.WithName("nddgenyang"))
+		log.Debug("validate resource map ...")
+
+		g, err := generator.NewGenerator(
+			generator.WithYangImportDirs(yangImportDirs),
+			generator.WithYangModuleDirs(yangModuleDirs),
+			generator.WithResourceMapInputFile(resourceMapInputFile),
+			generator.WithPrefix(prefix),
+			generator.WithLogging(log),
+			generator.WithDebug(debug),
+			generator.WithValidateOnly(true),
+		)
+		if err != nil {
+			return errors.Wrap(err, errCreateGenerator)
+		}
+
+		findings, err := g.Validate()
+		if err != nil {
+			log.Debug("Error", "error", err)
+			return err
+		}
+
+		var nbrErrors, nbrWarnings int
+		for _, f := range findings {
+			fmt.Printf("%s: [%s] %s\n", f.Severity, f.Check, f.Message)
+			switch f.Severity {
+			case generator.SeverityError:
+				nbrErrors++
+			case generator.SeverityWarning:
+				nbrWarnings++
+			}
+		}
+		fmt.Printf("%d errors, %d warnings\n", nbrErrors, nbrWarnings)
+		if nbrErrors > 0 || (strict && nbrWarnings > 0) {
+			return errors.New(errValidation)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringSliceVarP(&yangImportDirs, "yang-import-dirs", "i", []string{"/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/yang/21_03_0/ietf/"}, "Comma separated list of dirs to be recursively searched for import modules.")
+	validateCmd.Flags().StringSliceVarP(&yangModuleDirs, "yang-module-dirs", "m", []string{"/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/yang/21_03_0/srl/"}, "Comma separated list of dirs to be recursively searched for yang modules")
+	validateCmd.Flags().StringVarP(&resourceMapInputFile, "resource-map-input", "r", "/Users/henderiw/CodeProjects/go-dev/ndd-ygen/conf/resourceMapInputPlayK8s.yaml", "The resource map input file which resource should be validated")
+	validateCmd.Flags().StringVarP(&prefix, "prefix", "a", "srl", "The prefix that is added to the kubernetes api resource, the kinds of the resources must be unique")
+	validateCmd.Flags().BoolVarP(&strict, "strict", "", false, "fail the validation on warnings, like config subtrees which are not covered by a resource")
+}
@@ -6,15 +6,18 @@ require (
 	github.com/Masterminds/goutils v1.1.1 // indirect
 	github.com/Masterminds/semver v1.5.0 // indirect
 	github.com/Masterminds/sprig v2.22.0+incompatible
+	github.com/gobuffalo/flect v0.2.3
 	github.com/huandu/xstrings v1.3.2 // indirect
 	github.com/mitchellh/copystructure v1.2.0 // indirect
 	github.com/netw-device-driver/ndd-grpc v0.1.32
 	github.com/netw-device-driver/ndd-runtime v0.4.78
 	github.com/openconfig/goyang v0.2.7
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.2.1
 	github.com/stoewer/go-strcase v1.2.0
 	github.com/yndd/ndd-yang v0.1.53
+	golang.org/x/tools v0.1.3
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/apimachinery v0.21.3
 	sigs.k8s.io/controller-runtime v0.9.5
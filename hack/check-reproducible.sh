@@ -0,0 +1,46 @@
+#!/bin/sh
+# Copyright 2020 Wim Henderickx.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# check-reproducible.sh generates the provider twice in every output format from the
+# same inputs and fails when the generated trees are not byte-identical.
+#
+# usage: check-reproducible.sh <ndd-ygen binary> [resource map] [yang import dir] [yang module dir]
+set -e
+
+BINARY=${1:?usage: check-reproducible.sh <ndd-ygen binary> [resource map] [yang import dir] [yang module dir]}
+RESOURCE_MAP=${2:-conf/resourceMapInputPlayK8s.yaml}
+YANG_IMPORT_DIR=${3:-conf/yang/21_03_0/ietf}
+YANG_MODULE_DIR=${4:-conf/yang/21_03_0/srl}
+OUTPUT_FORMATS="k8s crds terraform proto jsonschema docs"
+
+TMP_DIR=$(mktemp -d)
+trap 'rm -rf "$TMP_DIR"' EXIT
+
+for run in 1 2; do
+	for format in $OUTPUT_FORMATS; do
+		"$BINARY" generate -d=false \
+			-r "$RESOURCE_MAP" -i "$YANG_IMPORT_DIR" -m "$YANG_MODULE_DIR" \
+			-o "$TMP_DIR/$run" --output-format "$format" > "$TMP_DIR/$run-$format.log" 2>&1 || {
+			cat "$TMP_DIR/$run-$format.log"
+			exit 1
+		}
+	done
+done
+
+if ! diff -r "$TMP_DIR/1" "$TMP_DIR/2"; then
+	echo "generated output is not reproducible"
+	exit 1
+fi
+echo "generated output is reproducible"
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"strconv"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/yndd/ndd-yang/pkg/container"
+)
+
+// rangeBounds returns the minimum and maximum of the range of an integer leaf. The
+// bounds are read from the yang entry since the range of the container entry does
+// not keep the sign and wraps the uint64 bounds. A bound is nil when the leaf has no
+// range or the bound does not fit in an int64, like the maximum of an uint64.
+func (g *Generator) rangeBounds(e *container.Entry) (min, max *int64) {
+	ye, ok := g.yangEntries[e]
+	if !ok || ye.Type == nil || len(ye.Type.Range) == 0 {
+		return nil, nil
+	}
+	r := ye.Type.Range
+	return yangInt(r[0].Min), yangInt(r[len(r)-1].Max)
+}
+
+// lengthBounds returns the minimum and maximum length of a string leaf, read from the
+// yang entry since the length of the container entry only keeps its first interval.
+// The bounds of a length of multiple intervals are the minimum of the first and the
+// maximum of the last interval. Both bounds are nil when the intervals are disjoint,
+// since the bounds would allow the lengths in between the intervals.
+func (g *Generator) lengthBounds(e *container.Entry) (min, max *int64) {
+	ye, ok := g.yangEntries[e]
+	if !ok || ye.Type == nil || len(ye.Type.Length) == 0 {
+		return nil, nil
+	}
+	l := ye.Type.Length
+	for i := 1; i < len(l); i++ {
+		prevMax, nextMin := yangInt(l[i-1].Max), yangInt(l[i].Min)
+		if prevMax == nil || nextMin == nil || *nextMin > *prevMax+1 {
+			return nil, nil
+		}
+	}
+	return yangInt(l[0].Min), yangInt(l[len(l)-1].Max)
+}
+
+// yangInt returns the yang number as an int64, nil when the number is a decimal or
+// does not fit in an int64
+func yangInt(n yang.Number) *int64 {
+	if n.IsDecimal() || n.Kind == yang.MinNumber || n.Kind == yang.MaxNumber {
+		return nil
+	}
+	v, err := n.Int()
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// rangeString returns the range of an integer leaf as min..max, the bounds that do
+// not fit in an int64 are left open
+func (g *Generator) rangeString(e *container.Entry) string {
+	return boundsString(g.rangeBounds(e))
+}
+
+// lengthString returns the length of a string leaf as min..max
+func (g *Generator) lengthString(e *container.Entry) string {
+	return boundsString(g.lengthBounds(e))
+}
+
+// boundsString returns the bounds as min..max, empty when there are no bounds
+func boundsString(min, max *int64) string {
+	if min == nil && max == nil {
+		return ""
+	}
+	s := ""
+	if min != nil {
+		s += strconv.FormatInt(*min, 10)
+	}
+	s += ".."
+	if max != nil {
+		s += strconv.FormatInt(*max, 10)
+	}
+	return s
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/yndd/ndd-yang/pkg/container"
+)
+
+// Choice is a yang choice within a container, only one of its cases can be set
+type Choice struct {
+	Name  string
+	Cases []*Case
+}
+
+// Case is a yang case of a choice, which holds the container entries of the case
+type Case struct {
+	Name    string
+	Entries []*container.Entry
+}
+
+// isChoiceOrCase returns true when the yang entry is a choice or case statement,
+// which are schema nodes only and are not part of the data tree
+func isChoiceOrCase(e *yang.Entry) bool {
+	return e.IsChoice() || e.IsCase()
+}
+
+// dataParent returns the parent of the yang entry in the data tree, which is the
+// first parent that is not a choice or case statement
+func dataParent(e *yang.Entry) *yang.Entry {
+	p := e.Parent
+	for p != nil && isChoiceOrCase(p) {
+		p = p.Parent
+	}
+	return p
+}
+
+// addChoiceEntry adds the container entry ce of yang entry e to the cases of the
+// container c it belongs to. Nested choices add the entry to every enclosing case.
+func (g *Generator) addChoiceEntry(c *container.Container, e *yang.Entry, ce *container.Entry) {
+	for p := e.Parent; p != nil && isChoiceOrCase(p); p = p.Parent {
+		if !p.IsCase() || p.Parent == nil {
+			continue
+		}
+		cs := g.choiceCase(g.containerChoice(c, p.Parent.Name), p.Name)
+		cs.Entries = append(cs.Entries, ce)
+	}
+}
+
+// containerChoice returns the choice of the container with the given name, the
+// choice is created when it does not exist
+func (g *Generator) containerChoice(c *container.Container, name string) *Choice {
+	for _, ch := range g.Choices[c] {
+		if ch.Name == name {
+			return ch
+		}
+	}
+	ch := &Choice{
+		Name:  name,
+		Cases: make([]*Case, 0),
+	}
+	g.Choices[c] = append(g.Choices[c], ch)
+	return ch
+}
+
+// choiceCase returns the case of the choice with the given name, the case is
+// created when it does not exist
+func (g *Generator) choiceCase(ch *Choice, name string) *Case {
+	for _, cs := range ch.Cases {
+		if cs.Name == name {
+			return cs
+		}
+	}
+	cs := &Case{
+		Name:    name,
+		Entries: make([]*container.Entry, 0),
+	}
+	ch.Cases = append(ch.Cases, cs)
+	return cs
+}
+
+// hasChoices returns true when the container or one of its child containers has choices
+func (g *Generator) hasChoices(c *container.Container) bool {
+	if len(g.Choices[c]) > 0 {
+		return true
+	}
+	for _, e := range c.Entries {
+		if e.Next != nil && g.hasChoices(e.Next) {
+			return true
+		}
+	}
+	return false
+}
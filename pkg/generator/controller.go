@@ -0,0 +1,180 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const managedFileName = "zz_generated.managed.go"
+
+// ControllerPathElem is an element of the path of a resource on the device
+type ControllerPathElem struct {
+	Name string
+	Keys []*ControllerPathKey
+}
+
+// ControllerPathKey is a key of a path element, the field is the go field in the
+// parameters of the resource that holds the value of the key
+type ControllerPathKey struct {
+	Name  string
+	Field string
+}
+
+// RenderManaged writes the methods of the generated api types that implement the
+// ndd-runtime managed resource interface, which are used by the controllers
+func (g *Generator) RenderManaged() error {
+	f := g.createGoFile(filepath.Join(g.Config.OutputDir, "api", g.Config.Version, managedFileName), nil)
+	defer f.Close()
+
+	s := struct {
+		Version string
+	}{
+		Version: g.Config.Version,
+	}
+	if err := g.executeTemplate(f, "managedHeader"+".tmpl", s); err != nil {
+		g.log.Debug("Write managed header error", "error", err)
+		return err
+	}
+	for _, r := range g.Resources {
+		f.forResource(r)
+		s := struct {
+			ResourceNameWithPrefix string
+		}{
+			ResourceNameWithPrefix: g.kind(r),
+		}
+		if err := g.executeTemplate(f, "managedResource"+".tmpl", s); err != nil {
+			g.log.Debug("Write managed resource error", "error", err)
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// RenderControllers writes a controller per resource, which reconciles the resource
+// with the device using the ndd-runtime managed reconciler
+func (g *Generator) RenderControllers() error {
+	dir := filepath.Join(g.Config.OutputDir, "controllers", g.Config.Prefix)
+	if err := g.output.MkdirAll(dir); err != nil {
+		return err
+	}
+
+	kinds := make([]string, 0, len(g.Resources))
+	for _, r := range g.Resources {
+		fileName := filepath.Join(dir, g.Config.Prefix+"-"+strcase.KebabCase(r.GetAbsoluteName())+"_controller.go")
+		if err := g.WriteController(fileName, r); err != nil {
+			g.log.Debug("Write controller error", "error", err)
+			return err
+		}
+		kinds = append(kinds, g.kind(r))
+	}
+
+	f := g.createGoFile(filepath.Join(dir, "setup.go"), nil)
+	defer f.Close()
+	s := struct {
+		Package       string
+		Version       string
+		ApiImportPath string
+		Kinds         []string
+	}{
+		Package:       g.Config.Prefix,
+		Version:       g.Config.Version,
+		ApiImportPath: g.apiImportPath(),
+		Kinds:         kinds,
+	}
+	if err := g.executeTemplate(f, "controllerSetup"+".tmpl", s); err != nil {
+		g.log.Debug("Write controller setup error", "error", err)
+		return err
+	}
+	return f.Close()
+}
+
+// WriteController
+func (g *Generator) WriteController(fileName string, r *resource.Resource) error {
+	f := g.createGoFile(fileName, r)
+	defer f.Close()
+
+	var parentPathElems []*ControllerPathElem
+	if r.DependsOn != nil {
+		parentPathElems = g.controllerPathElems(r.DependsOn, r)
+	}
+	s := struct {
+		Package         string
+		Version         string
+		ApiImportPath   string
+		Kind            string
+		ResourceName    string
+		RootElement     string
+		RootKey         bool
+		Level           int
+		PathElems       []*ControllerPathElem
+		ParentPathElems []*ControllerPathElem
+	}{
+		Package:         g.Config.Prefix,
+		Version:         g.Config.Version,
+		ApiImportPath:   g.apiImportPath(),
+		Kind:            g.kind(r),
+		ResourceName:    r.GetResourceNameWithPrefix(""),
+		RootElement:     r.ResourceLastElement(),
+		RootKey:         r.RootContainerEntry != nil && r.RootContainerEntry.Key != "",
+		Level:           r.GetAbsoluteLevel(),
+		PathElems:       g.controllerPathElems(r, r),
+		ParentPathElems: parentPathElems,
+	}
+	if err := g.executeTemplate(f, "controller"+".tmpl", s); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// apiImportPath returns the import path of the generated api package
+func (g *Generator) apiImportPath() string {
+	return g.Config.Module + "/api/" + g.Config.Version
+}
+
+// controllerPathElems returns the path elements of resource r on the device, using
+// the keys of the hierarchical resources in the parameters of resource self
+func (g *Generator) controllerPathElems(r, self *resource.Resource) []*ControllerPathElem {
+	elems := make([]*ControllerPathElem, 0)
+	path := r.Path.GetElem()
+	if r.DependsOn != nil {
+		elems = g.controllerPathElems(r.DependsOn, self)
+	} else if len(path) > 0 {
+		// the first element of a root resource is the yang module
+		path = path[1:]
+	}
+	for i, pe := range path {
+		elem := &ControllerPathElem{Name: pe.GetName()}
+		// the last element of the path is the root container of the resource
+		if i == len(path)-1 && r.RootContainerEntry != nil {
+			for _, k := range strings.Fields(r.RootContainerEntry.Key) {
+				field := g.kind(r) + "." + strcase.UpperCamelCase(k)
+				if r != self {
+					// the parameters hold a field per key of the hierarchical parents
+					field = g.hierarchyKeyField(r, k)
+				}
+				elem.Keys = append(elem.Keys, &ControllerPathKey{Name: k, Field: field})
+			}
+		}
+		elems = append(elems, elem)
+	}
+	return elems
+}
@@ -0,0 +1,411 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	errCrdMarshal = "cannot marshal custom resource definition"
+	errCrdWrite   = "cannot write custom resource definition"
+)
+
+// CustomResourceDefinition is the subset of the apiextensions.k8s.io/v1
+// CustomResourceDefinition which is generated from the yang model
+type CustomResourceDefinition struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   CrdMetadata `yaml:"metadata"`
+	Spec       CrdSpec     `yaml:"spec"`
+}
+
+// CrdMetadata struct
+type CrdMetadata struct {
+	Name string `yaml:"name"`
+}
+
+// CrdSpec struct
+type CrdSpec struct {
+	Group    string       `yaml:"group"`
+	Names    CrdNames     `yaml:"names"`
+	Scope    string       `yaml:"scope"`
+	Versions []CrdVersion `yaml:"versions"`
+}
+
+// CrdNames struct
+type CrdNames struct {
+	Categories []string `yaml:"categories,omitempty"`
+	Kind       string   `yaml:"kind"`
+	ListKind   string   `yaml:"listKind"`
+	Plural     string   `yaml:"plural"`
+	ShortNames []string `yaml:"shortNames,omitempty"`
+	Singular   string   `yaml:"singular"`
+}
+
+// CrdVersion struct
+type CrdVersion struct {
+	AdditionalPrinterColumns []CrdPrinterColumn `yaml:"additionalPrinterColumns,omitempty"`
+	Name                     string             `yaml:"name"`
+	Schema                   CrdValidation      `yaml:"schema"`
+	Served                   bool               `yaml:"served"`
+	Storage                  bool               `yaml:"storage"`
+	Subresources             CrdSubresources    `yaml:"subresources"`
+}
+
+// CrdPrinterColumn struct
+type CrdPrinterColumn struct {
+	Description string `yaml:"description,omitempty"`
+	JSONPath    string `yaml:"jsonPath"`
+	Name        string `yaml:"name"`
+	Priority    int32  `yaml:"priority,omitempty"`
+	Type        string `yaml:"type"`
+}
+
+// CrdValidation struct
+type CrdValidation struct {
+	OpenAPIV3Schema *JSONSchemaProps `yaml:"openAPIV3Schema"`
+}
+
+// CrdSubresources struct
+type CrdSubresources struct {
+	Status map[string]string `yaml:"status"`
+}
+
+// JSONSchemaProps is the subset of the OpenAPI v3 schema that is used to
+// describe the yang containers and leafs
+type JSONSchemaProps struct {
+	Description  string                      `yaml:"description,omitempty"`
+	Type         string                      `yaml:"type,omitempty"`
+	Format       string                      `yaml:"format,omitempty"`
+	Default      interface{}                 `yaml:"default,omitempty"`
+	Enum         []interface{}               `yaml:"enum,omitempty"`
+	Minimum      *int64                      `yaml:"minimum,omitempty"`
+	Maximum      *int64                      `yaml:"maximum,omitempty"`
+	MinLength    *int64                      `yaml:"minLength,omitempty"`
+	MaxLength    *int64                      `yaml:"maxLength,omitempty"`
+	Pattern      string                      `yaml:"pattern,omitempty"`
+	Items        *JSONSchemaProps            `yaml:"items,omitempty"`
+	MinItems     *int64                      `yaml:"minItems,omitempty"`
+	MaxItems     *int64                      `yaml:"maxItems,omitempty"`
+	Properties   map[string]*JSONSchemaProps `yaml:"properties,omitempty"`
+	Required     []string                    `yaml:"required,omitempty"`
+	XValidations []CrdValidationRule         `yaml:"x-kubernetes-validations,omitempty"`
+}
+
+// CrdValidationRule struct
+type CrdValidationRule struct {
+	Rule    string `yaml:"rule"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// RenderCrds writes a CustomResourceDefinition for every resource
+func (g *Generator) RenderCrds() error {
+	dir := filepath.Join(g.Config.OutputDir, "crds")
+	if err := g.output.MkdirAll(dir); err != nil {
+		return err
+	}
+	for _, r := range g.Resources {
+		crd := g.BuildCrd(r)
+		b, err := yaml.Marshal(crd)
+		if err != nil {
+			return errors.Wrap(err, errCrdMarshal)
+		}
+		fileName := filepath.Join(dir, g.Config.ApiGroup+"_"+crd.Spec.Names.Plural+".yaml")
+		if err := g.output.WriteFile(fileName, append([]byte("---\n"), b...)); err != nil {
+			return errors.Wrap(err, errCrdWrite)
+		}
+	}
+	return g.RenderValidationReport()
+}
+
+// BuildCrd builds the CustomResourceDefinition of a resource from the container
+// trees of the resource
+func (g *Generator) BuildCrd(r *resource.Resource) *CustomResourceDefinition {
+	kind := g.kind(r)
+	plural := kindPlural(kind)
+
+	return &CustomResourceDefinition{
+		APIVersion: "apiextensions.k8s.io/v1",
+		Kind:       "CustomResourceDefinition",
+		Metadata: CrdMetadata{
+			Name: plural + "." + g.Config.ApiGroup,
+		},
+		Spec: CrdSpec{
+			Group: g.Config.ApiGroup,
+			Names: CrdNames{
+				Categories: g.categories(r),
+				Kind:       kind,
+				ListKind:   kind + "List",
+				Plural:     plural,
+				ShortNames: g.shortNames(r),
+				Singular:   strings.ToLower(kind),
+			},
+			Scope: g.scope(r),
+			Versions: []CrdVersion{
+				{
+					AdditionalPrinterColumns: g.printColumns(r),
+					Name:                     g.Config.Version,
+					Schema: CrdValidation{
+						OpenAPIV3Schema: g.crdResourceSchema(r, kind),
+					},
+					Served:  true,
+					Storage: true,
+					Subresources: CrdSubresources{
+						Status: map[string]string{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// crdPrinterColumns returns the default printer columns of the resources, which are
+// also rendered in the kubebuilder markers of the resource template
+func crdPrinterColumns() []CrdPrinterColumn {
+	return []CrdPrinterColumn{
+		{Name: "TARGET", Type: "string", JSONPath: ".status.conditions[?(@.kind=='TargetFound')].status"},
+		{Name: "STATUS", Type: "string", JSONPath: ".status.conditions[?(@.kind=='Ready')].status"},
+		{Name: "SYNC", Type: "string", JSONPath: ".status.conditions[?(@.kind=='Synced')].status"},
+		{Name: "LOCALLEAFREF", Type: "string", JSONPath: ".status.conditions[?(@.kind=='InternalLeafrefValidationSuccess')].status"},
+		{Name: "EXTLEAFREF", Type: "string", JSONPath: ".status.conditions[?(@.kind=='ExternalLeafrefValidationSuccess')].status"},
+		{Name: "PARENTDEP", Type: "string", JSONPath: ".status.conditions[?(@.kind=='ParentValidationSuccess')].status"},
+		{Name: "AGE", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+	}
+}
+
+// crdResourceSchema returns the schema of the kubernetes api object of the resource
+func (g *Generator) crdResourceSchema(r *resource.Resource, kind string) *JSONSchemaProps {
+	resourceName := strcase.KebabCase(r.GetResourceNameWithPrefix(""))
+
+	// the parameters hold the keys of the hierarchical resources and the root container
+	parameters := &JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]*JSONSchemaProps{},
+	}
+	for _, k := range g.hierarchyKeys(r) {
+		parameters.Properties[k.JSONName] = g.crdLeafSchema(&container.Entry{Type: k.Type})
+		parameters.Required = append(parameters.Required, k.JSONName)
+	}
+	parameters.Properties[resourceName] = g.crdContainerSchema(r.Container)
+	parameters.Properties[resourceName].Description = g.containerDescription(r.Container)
+	parameters.Required = append(parameters.Required, resourceName)
+
+	observation := &JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]*JSONSchemaProps{},
+	}
+	if s, ok := g.States[r]; ok {
+		observation.Properties[resourceName] = g.crdContainerSchema(s.Container)
+		observation.Properties[resourceName].Description = g.containerDescription(s.Container)
+	}
+
+	return &JSONSchemaProps{
+		Description: kind + " is the Schema for the " + kind + " API",
+		Type:        "object",
+		Properties: map[string]*JSONSchemaProps{
+			"apiVersion": {Type: "string"},
+			"kind":       {Type: "string"},
+			"metadata":   {Type: "object"},
+			"spec": {
+				Description: "A " + kind + "Spec defines the desired state of a " + kind + ".",
+				Type:        "object",
+				Properties: map[string]*JSONSchemaProps{
+					"active": {
+						Description: "Active specifies if the managed resource is active or not",
+						Type:        "boolean",
+						Default:     true,
+					},
+					"deletionPolicy": {
+						Description: "DeletionPolicy specifies what will happen to the underlying external when this managed resource is deleted - either \"Delete\" or \"Orphan\" the external resource.",
+						Type:        "string",
+						Default:     "Delete",
+						Enum:        []interface{}{"Orphan", "Delete"},
+					},
+					"networkNodeRef": {
+						Description: "NetworkNodeReference specifies which network node will be used to create, observe, update, and delete this managed resource",
+						Type:        "object",
+						Default:     map[string]string{"name": "default"},
+						Properties: map[string]*JSONSchemaProps{
+							"name": {Type: "string"},
+						},
+						Required: []string{"name"},
+					},
+					"forNetworkNode": parameters,
+				},
+				Required: []string{"forNetworkNode"},
+			},
+			"status": {
+				Description: "A " + kind + "Status represents the observed state of a " + kind + ".",
+				Type:        "object",
+				Properties: map[string]*JSONSchemaProps{
+					"conditions": {
+						Type: "array",
+						Items: &JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]*JSONSchemaProps{
+								"kind":                  {Type: "string"},
+								"status":                {Type: "string"},
+								"lastTransitionTime":    {Type: "string", Format: "date-time"},
+								"reason":                {Type: "string"},
+								"message":               {Type: "string"},
+								"externalResourceNames": {Type: "array", Items: &JSONSchemaProps{Type: "string"}},
+							},
+							Required: []string{"kind", "lastTransitionTime", "reason", "status"},
+						},
+					},
+					"target": {
+						Type:  "array",
+						Items: &JSONSchemaProps{Type: "string"},
+					},
+					"atNetworkNode": observation,
+				},
+			},
+		},
+	}
+}
+
+// crdContainerSchema returns the object schema of a container
+func (g *Generator) crdContainerSchema(c *container.Container) *JSONSchemaProps {
+	s := &JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]*JSONSchemaProps{},
+	}
+	for _, rule := range g.Rules[c] {
+		s.XValidations = append(s.XValidations, CrdValidationRule{Rule: rule.Rule, Message: rule.Message})
+	}
+	for _, e := range g.containerEntries(c) {
+		name := strcase.KebabCase(e.Name)
+		switch {
+		case e.LeafList != nil:
+			// leaf-list in the container
+			s.Properties[name] = g.crdLeafListSchema(e)
+			if e.Mandatory || e.LeafList.MinElements > 0 {
+				s.Required = append(s.Required, name)
+			}
+		case e.Next != nil && e.Key != "":
+			// list in the container
+			s.Properties[name] = &JSONSchemaProps{
+				Type:  "array",
+				Items: g.crdContainerSchema(e.Next),
+			}
+		case e.Next != nil:
+			// container in the container
+			s.Properties[name] = g.crdContainerSchema(e.Next)
+		default:
+			// regular leaf in the container
+			s.Properties[name] = g.crdLeafSchema(e.Entry)
+			if e.Mandatory {
+				s.Required = append(s.Required, name)
+			}
+		}
+		s.Properties[name].Description = e.Description
+	}
+	return s
+}
+
+// crdLeafSchema returns the schema of a leaf, using the type, range, length,
+// pattern, enum and default information of the container entry
+func (g *Generator) crdLeafSchema(e *container.Entry) *JSONSchemaProps {
+	s := &JSONSchemaProps{}
+	switch e.Type {
+	case "int8", "int16", "int32", "uint8", "uint16":
+		s.Type = "integer"
+		s.Format = "int32"
+	case "int64", "uint32", "uint64":
+		s.Type = "integer"
+		s.Format = "int64"
+	case "bool":
+		s.Type = "boolean"
+	default:
+		s.Type = "string"
+	}
+
+	if s.Type == "integer" {
+		s.Minimum, s.Maximum = g.rangeBounds(e)
+	}
+	s.MinLength, s.MaxLength = g.lengthBounds(e)
+	s.Pattern = enforceablePattern(e)
+	for _, enum := range e.Enum {
+		s.Enum = append(s.Enum, enum)
+	}
+	if e.Default != "" {
+		s.Default = crdDefault(s.Type, e.Default)
+	}
+	return s
+}
+
+// crdLeafListSchema returns the array schema of a leaf-list, the items have the
+// schema of a leaf
+func (g *Generator) crdLeafListSchema(e *ContainerEntry) *JSONSchemaProps {
+	s := &JSONSchemaProps{
+		Type:  "array",
+		Items: g.crdLeafSchema(e.Entry),
+	}
+	// the default of a leaf-list applies to the list and not to its items
+	s.Items.Default = nil
+	if e.LeafList.MinElements > 0 {
+		min := int64(e.LeafList.MinElements)
+		s.MinItems = &min
+	}
+	if e.LeafList.MaxElements > 0 {
+		max := int64(e.LeafList.MaxElements)
+		s.MaxItems = &max
+	}
+	return s
+}
+
+// crdPattern returns an OpenAPI pattern from the yang patterns. yang patterns
+// are implicitly anchored and a value must match all of them, since lookaheads
+// are not supported by the api server the patterns are combined as alternatives
+// which never rejects a valid value.
+func crdPattern(patterns []string) string {
+	return "^(" + strings.Join(patterns, "|") + ")$"
+}
+
+// enforceablePattern returns the pattern of the leaf which can be enforced on its value,
+// empty when the leaf has no patterns. The patterns of a union only apply to some of its
+// member types, hence they cannot be enforced on the value as a whole.
+func enforceablePattern(e *container.Entry) string {
+	if len(e.Pattern) == 0 || e.Union {
+		return ""
+	}
+	return crdPattern(e.Pattern)
+}
+
+// crdDefault returns the default value with the json type of the leaf
+func crdDefault(t, d string) interface{} {
+	switch t {
+	case "integer":
+		if i, err := strconv.ParseInt(d, 10, 64); err == nil {
+			return i
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(d); err == nil {
+			return b
+		}
+	}
+	return d
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"io"
+	"path/filepath"
+
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const deepCopyFileName = "zz_generated.deepcopy.go"
+
+// RenderDeepCopy writes the deepcopy functions of all the generated api types
+// of the resources in a single file of the api package
+func (g *Generator) RenderDeepCopy() error {
+	f := g.createGoFile(filepath.Join(g.Config.OutputDir, "api", g.Config.Version, deepCopyFileName), nil)
+	defer f.Close()
+
+	if err := g.WriteDeepCopyHeader(f); err != nil {
+		g.log.Debug("Write deepcopy header error", "error", err)
+		return err
+	}
+	for _, r := range g.Resources {
+		f.forResource(r)
+		for _, c := range r.ContainerList {
+			if err := g.WriteDeepCopyContainer(f, c); err != nil {
+				g.log.Debug("Write deepcopy container error", "error", err)
+				return err
+			}
+		}
+		if st, ok := g.States[r]; ok {
+			for _, c := range st.ContainerList {
+				if err := g.WriteDeepCopyContainer(f, c); err != nil {
+					g.log.Debug("Write deepcopy state container error", "error", err)
+					return err
+				}
+			}
+		}
+		if err := g.WriteDeepCopyResource(f, r); err != nil {
+			g.log.Debug("Write deepcopy resource error", "error", err)
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// WriteDeepCopyHeader
+func (g *Generator) WriteDeepCopyHeader(w io.Writer) error {
+	s := struct {
+		Version string
+	}{
+		Version: g.Config.Version,
+	}
+	return g.executeTemplate(w, "deepcopyHeader"+".tmpl", s)
+}
+
+// WriteDeepCopyContainer
+func (g *Generator) WriteDeepCopyContainer(w io.Writer, c *container.Container) error {
+	s := struct {
+		Name     string
+		TypeName string
+		Entries  []*ContainerEntry
+	}{
+		Name:     c.GetFullName(),
+		TypeName: g.goTypeName(c),
+		Entries:  g.containerEntries(c),
+	}
+	return g.executeTemplate(w, "deepcopyContainer"+".tmpl", s)
+}
+
+// WriteDeepCopyResource writes the deepcopy functions of the types that wrap the
+// containers of the resource into a kubernetes api object
+func (g *Generator) WriteDeepCopyResource(w io.Writer, r *resource.Resource) error {
+	s := struct {
+		Prefix                 string
+		ResourceLastElement    string
+		ResourceNameWithPrefix string
+		StateRoot              string
+		HierarchyKeys          []*HierarchyKey
+	}{
+		Prefix:                 g.Config.Prefix,
+		ResourceLastElement:    g.rootTypeName(r),
+		ResourceNameWithPrefix: g.kind(r),
+		StateRoot:              g.stateRoot(r),
+		HierarchyKeys:          g.hierarchyKeys(r),
+	}
+	return g.executeTemplate(w, "deepcopyResource"+".tmpl", s)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/yndd/ndd-yang/pkg/container"
+)
+
+// commentWidth is the maximum width of the text of a generated comment line
+const commentWidth = 80
+
+// yangDescription returns the description of the yang entry on a single line
+func yangDescription(e *yang.Entry) string {
+	if e == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(e.Description), " ")
+}
+
+// containerDescription returns the description of the yang entry of the container
+func (g *Generator) containerDescription(c *container.Container) string {
+	return yangDescription(g.yangContainers[c])
+}
+
+// commentLines wraps the description into the lines of a go comment. A line never
+// starts with a + since the line would be interpreted as a kubebuilder marker.
+func commentLines(description string) []string {
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(description) {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) > commentWidth && !strings.HasPrefix(word, "+"):
+			lines = append(lines, line)
+			line = word
+		default:
+			line += " " + word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pkg/errors"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const (
+	errYangProcessing = "cannot process yang modules"
+)
+
+var (
+	// positionRegexp matches the file:line:col prefix of the goyang errors
+	positionRegexp = regexp.MustCompile(`^(.+):(\d+):(\d+): (.*)$`)
+	// missingModuleRegexp matches the goyang error of an import that cannot be found
+	missingModuleRegexp = regexp.MustCompile(`^no such module: (.*)$`)
+)
+
+// Diagnostic is an error of processing the yang modules with the position in the yang
+// file the error is found at
+type Diagnostic struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	// the resources which are generated from the yang file of the error
+	Resources []string
+}
+
+// Position returns the file:line:col position of the diagnostic
+func (d *Diagnostic) Position() string {
+	switch {
+	case d.File == "":
+		return "unknown"
+	case d.Line == 0:
+		return d.File
+	default:
+		return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+}
+
+func (d *Diagnostic) String() string {
+	s := d.Position() + ": " + d.Message
+	if len(d.Resources) > 0 {
+		s += " (affects resources " + strings.Join(d.Resources, ", ") + ")"
+	}
+	return s
+}
+
+// Diagnostics returns the errors of processing the yang modules
+func (g *Generator) Diagnostics() []*Diagnostic {
+	return g.diagnostics
+}
+
+// diagnosticsError returns the error of the diagnostics
+func diagnosticsError(diagnostics []*Diagnostic) error {
+	msgs := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		msgs = append(msgs, d.String())
+	}
+	return errors.Errorf("%s: %s", errYangProcessing, strings.Join(msgs, "; "))
+}
+
+// newDiagnostics returns the diagnostics of the errors of processing the yang modules,
+// the errors of imports that cannot be found are positioned at the import statements
+func newDiagnostics(ms *yang.Modules, errs []error) []*Diagnostic {
+	diagnostics := make([]*Diagnostic, 0, len(errs))
+	found := make(map[string]bool)
+	add := func(d *Diagnostic) {
+		if !found[d.String()] {
+			found[d.String()] = true
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	for _, err := range errs {
+		msg := err.Error()
+		if m := missingModuleRegexp.FindStringSubmatch(msg); m != nil {
+			if imports := findImports(ms, m[1]); len(imports) > 0 {
+				for _, i := range imports {
+					add(newDiagnostic(yang.Source(i) + ": " + msg))
+				}
+				continue
+			}
+		}
+		add(newDiagnostic(msg))
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return diagnostics
+}
+
+// newDiagnostic returns the diagnostic of a goyang error message
+func newDiagnostic(msg string) *Diagnostic {
+	m := positionRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return &Diagnostic{Message: msg}
+	}
+	line, _ := strconv.Atoi(m[2])
+	col, _ := strconv.Atoi(m[3])
+	return &Diagnostic{File: m[1], Line: line, Column: col, Message: m[4]}
+}
+
+// findImports returns the import statements of the module in the modules and submodules
+func findImports(ms *yang.Modules, module string) []*yang.Import {
+	imports := make([]*yang.Import, 0)
+	seen := make(map[*yang.Module]bool)
+	for _, mods := range []map[string]*yang.Module{ms.Modules, ms.SubModules} {
+		for _, m := range mods {
+			if seen[m] {
+				continue
+			}
+			seen[m] = true
+			for _, i := range m.Import {
+				if i.Name == module {
+					imports = append(imports, i)
+				}
+			}
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		return yang.Source(imports[i]) < yang.Source(imports[j])
+	})
+	return imports
+}
+
+// affectedResources adds the resources to the diagnostics which are generated from the
+// yang statement of the diagnostic, a diagnostic which is not positioned at a statement
+// of a resource, like an import, affects the resources generated from the yang file of
+// the diagnostic. The resources of which the path does not resolve are skipped.
+func (g *Generator) affectedResources() {
+	if len(g.diagnostics) == 0 {
+		return
+	}
+	modules := g.yangModules()
+	entries := make(map[*resource.Resource]*yang.Entry)
+	resourceEntries := make(map[*yang.Entry]bool)
+	for _, r := range g.Resources {
+		elems := pathElemNames(r.GetAbsoluteGnmiPath())
+		m, ok := modules[elems[0]]
+		if !ok {
+			continue
+		}
+		if e, problem := resolvePath("", m, elems[1:]); problem == "" {
+			entries[r] = e
+			resourceEntries[e] = true
+		}
+	}
+
+	positions := make(map[*resource.Resource]map[string]bool)
+	files := make(map[*resource.Resource]map[string]bool)
+	for r, e := range entries {
+		positions[r] = make(map[string]bool)
+		files[r] = make(map[string]bool)
+		yangPositions(e, resourceEntries, positions[r], files[r])
+	}
+	for _, d := range g.diagnostics {
+		for _, r := range g.Resources {
+			if positions[r][d.File+":"+strconv.Itoa(d.Line)] {
+				d.Resources = append(d.Resources, *r.GetAbsoluteXPath())
+			}
+		}
+		if len(d.Resources) > 0 {
+			continue
+		}
+		for _, r := range g.Resources {
+			if files[r][d.File] {
+				d.Resources = append(d.Resources, *r.GetAbsoluteXPath())
+			}
+		}
+	}
+}
+
+// yangPositions adds the file:line positions and the files of the statements the yang
+// entry and its children are generated from, the children which are the root of another
+// resource are skipped
+func yangPositions(e *yang.Entry, resourceEntries map[*yang.Entry]bool, positions, files map[string]bool) {
+	if e.Node != nil && e.Node.Statement() != nil {
+		statementPositions(e.Node.Statement(), positions, files)
+	}
+	for _, c := range e.Dir {
+		if !resourceEntries[c] {
+			yangPositions(c, resourceEntries, positions, files)
+		}
+	}
+}
+
+// statementPositions adds the positions of the statement and its substatements, without
+// the data definition statements which have an entry of their own
+func statementPositions(s *yang.Statement, positions, files map[string]bool) {
+	d := newDiagnostic(s.Location() + ": ")
+	positions[d.File+":"+strconv.Itoa(d.Line)] = true
+	files[d.File] = true
+	for _, ss := range s.SubStatements() {
+		switch ss.Keyword {
+		case "container", "list", "leaf", "leaf-list", "choice", "case", "anydata", "anyxml",
+			"uses", "augment", "action", "notification":
+			continue
+		}
+		statementPositions(ss, positions, files)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	config "github.com/netw-device-driver/ndd-grpc/config/configpb"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const (
+	// OutputFormatDocs renders the markdown api reference of the resources
+	OutputFormatDocs = "docs"
+
+	docsIndexFileName = "README.md"
+)
+
+// DocResource is the api reference of a resource
+type DocResource struct {
+	Kind             string
+	FileName         string
+	XPath            string
+	Description      string
+	HierarchyKeys    []*DocField
+	Fields           []*DocField
+	LocalLeafRefs    []*DocLeafRef
+	ExternalLeafRefs []*DocLeafRef
+}
+
+// DocField is a field of a resource in the api reference
+type DocField struct {
+	Path        string
+	Type        string
+	Constraints []string
+	Default     string
+	Description string
+}
+
+// DocLeafRef is a leafref of a resource in the api reference
+type DocLeafRef struct {
+	LocalPath  string
+	RemotePath string
+}
+
+// RenderDocs writes a markdown page per resource with the fields, constraints and
+// leafrefs of the resource, and an index of the resources
+func (g *Generator) RenderDocs() error {
+	dir := filepath.Join(g.Config.OutputDir, "docs")
+	if err := g.output.MkdirAll(dir); err != nil {
+		return err
+	}
+
+	resources := make([]*DocResource, 0, len(g.Resources))
+	for _, r := range g.Resources {
+		dr := g.docResource(r)
+		f, err := g.output.Create(filepath.Join(dir, dr.FileName))
+		if err != nil {
+			return err
+		}
+		s := struct {
+			ApiGroup string
+			Version  string
+			Resource *DocResource
+		}{
+			ApiGroup: g.Config.ApiGroup,
+			Version:  g.Config.Version,
+			Resource: dr,
+		}
+		if err := g.executeTemplate(f, "docsResource"+".tmpl", s); err != nil {
+			g.log.Debug("Write docs resource error", "error", err)
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		resources = append(resources, dr)
+	}
+
+	f, err := g.output.Create(filepath.Join(dir, docsIndexFileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := struct {
+		ApiGroup  string
+		Version   string
+		Resources []*DocResource
+	}{
+		ApiGroup:  g.Config.ApiGroup,
+		Version:   g.Config.Version,
+		Resources: resources,
+	}
+	if err := g.executeTemplate(f, "docsIndex"+".tmpl", s); err != nil {
+		g.log.Debug("Write docs index error", "error", err)
+		return err
+	}
+	return f.Close()
+}
+
+// docResource returns the api reference of the resource
+func (g *Generator) docResource(r *resource.Resource) *DocResource {
+	dr := &DocResource{
+		Kind:     g.kind(r),
+		FileName: g.Config.Prefix + "-" + strcase.KebabCase(r.GetAbsoluteName()) + ".md",
+		XPath:    *r.GetAbsoluteXPath(),
+	}
+	if ye, ok := g.yangEntries[r.RootContainerEntry]; ok {
+		dr.Description = docText(ye.Description)
+	}
+	for _, k := range g.hierarchyKeys(r) {
+		dr.HierarchyKeys = append(dr.HierarchyKeys, &DocField{
+			Path:        k.JSONName,
+			Type:        k.Type,
+			Description: "the " + k.Key + " of the " + k.Name + " this resource belongs to",
+		})
+	}
+	if r.Container != nil {
+		dr.Fields = g.docFields(r.Container, "")
+	}
+	for _, lr := range r.LocalLeafRefs {
+		dr.LocalLeafRefs = append(dr.LocalLeafRefs, g.docLeafRef(lr.LocalPath, lr.RemotePath))
+	}
+	for _, lr := range r.ExternalLeafRefs {
+		dr.ExternalLeafRefs = append(dr.ExternalLeafRefs, g.docLeafRef(lr.LocalPath, lr.RemotePath))
+	}
+	return dr
+}
+
+// docFields returns the fields of the container and its child containers, the path
+// of a field is relative to the root container of the resource
+func (g *Generator) docFields(c *container.Container, prefix string) []*DocField {
+	fields := make([]*DocField, 0, len(c.Entries))
+	for _, e := range g.containerEntries(c) {
+		f := &DocField{
+			Path:    prefix + e.Name,
+			Default: docText(e.Default),
+		}
+		if ye, ok := g.yangEntries[e.Entry]; ok {
+			f.Description = docText(ye.Description)
+		}
+		if e.Mandatory {
+			f.Constraints = append(f.Constraints, "mandatory")
+		}
+		switch {
+		case e.Next != nil && e.Key != "":
+			f.Type = "list"
+			f.Constraints = append(f.Constraints, "key: "+e.Key)
+		case e.Next != nil:
+			f.Type = "container"
+		default:
+			f.Type = e.Type
+			if en, ok := g.Enums[e.Type]; ok {
+				f.Type = en.Name + " (" + en.Kind + ")"
+				values := make([]string, 0, len(en.Values))
+				for _, v := range en.Values {
+					values = append(values, v.Value)
+				}
+				f.Constraints = append(f.Constraints, "values: "+docText(strings.Join(values, ", ")))
+			}
+			if e.LeafList != nil {
+				f.Type = "[]" + f.Type
+				if e.LeafList.MinElements > 0 {
+					f.Constraints = append(f.Constraints, "min-elements: "+strconv.FormatUint(e.LeafList.MinElements, 10))
+				}
+				if e.LeafList.MaxElements > 0 {
+					f.Constraints = append(f.Constraints, "max-elements: "+strconv.FormatUint(e.LeafList.MaxElements, 10))
+				}
+			}
+			if r := g.rangeString(e.Entry); r != "" {
+				f.Constraints = append(f.Constraints, "range: "+r)
+			}
+			if l := g.lengthString(e.Entry); l != "" {
+				f.Constraints = append(f.Constraints, "length: "+l)
+			}
+			for _, p := range e.Pattern {
+				f.Constraints = append(f.Constraints, "pattern: `"+docText(p)+"`")
+			}
+		}
+		fields = append(fields, f)
+		if e.Next != nil {
+			fields = append(fields, g.docFields(e.Next, f.Path+"/")...)
+		}
+	}
+	return fields
+}
+
+// docLeafRef returns the leafref with the paths as xpaths without keys
+func (g *Generator) docLeafRef(localPath, remotePath *config.Path) *DocLeafRef {
+	return &DocLeafRef{
+		LocalPath:  *g.parser.ConfigGnmiPathToXPath(localPath, false),
+		RemotePath: *g.parser.ConfigGnmiPathToXPath(remotePath, false),
+	}
+}
+
+// docText returns the yang text on a single line with escaped pipes, such that it
+// can be used in a markdown table
+func docText(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", "\\|")
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+)
+
+const enumFileName = "zz_generated.enum.go"
+
+// nonIdentifierChars matches the characters of a yang value that cannot be used in a go identifier
+var nonIdentifierChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// Enum is a named go string type, which is generated for a yang enumeration or for
+// the base identity of an identityref
+type Enum struct {
+	Name     string       // the name of the go type
+	YangName string       // the name of the typedef, identity base or leaf in yang
+	Kind     string       // enumeration or identityref
+	Values   []*EnumValue // the values of the enumeration or the identities derived from the base
+	yang     []string     // the yang values, used to find enums which share the same values
+}
+
+// EnumValue is a constant of an Enum
+type EnumValue struct {
+	Name  string // the name of the go constant
+	Value string // the yang value
+}
+
+// addEnum changes the type of the container entry ce to a named enum type, when the
+// yang entry is an enumeration or identityref. Entries which use the same typedef or
+// identity base share the same enum type.
+func (g *Generator) addEnum(c *container.Container, e *yang.Entry, ce *container.Entry) {
+	if e.Type == nil {
+		return
+	}
+	var name, yangName string
+	var values []string
+	switch e.Type.Kind {
+	case yang.Yenum:
+		if e.Type.Name != "enumeration" {
+			// the enumeration is defined in a typedef
+			name = e.Type.Name
+			yangName = e.Type.Name
+		} else {
+			name = c.GetFullName() + "-" + e.Name
+			yangName = e.Name
+		}
+		values = e.Type.Enum.Names()
+	case yang.Yidentityref:
+		if e.Type.IdentityBase == nil {
+			return
+		}
+		name = e.Type.IdentityBase.Name
+		yangName = e.Type.IdentityBase.PrefixedName()
+		// the values hold all the derived identities, also from other modules
+		for _, i := range e.Type.IdentityBase.Values {
+			values = append(values, i.Name)
+		}
+		values = uniqueStrings(values)
+		sort.Strings(values)
+	default:
+		return
+	}
+	if len(values) == 0 {
+		return
+	}
+	ce.Type = g.enum(strcase.UpperCamelCase(name), yangName, e.Type.Kind.String(), values).Name
+}
+
+// enum returns the enum with the given name and values, the enum is created when it
+// does not exist. An enum with the same name but different values gets a numbered name.
+func (g *Generator) enum(name, yangName, kind string, values []string) *Enum {
+	goName := name
+	for i := 2; ; i++ {
+		en, ok := g.Enums[goName]
+		if !ok {
+			break
+		}
+		if equalStrings(en.yang, values) {
+			return en
+		}
+		goName = name + strconv.Itoa(i)
+	}
+	en := &Enum{
+		Name:     goName,
+		YangName: yangName,
+		Kind:     kind,
+		Values:   make([]*EnumValue, 0, len(values)),
+		yang:     values,
+	}
+	used := make(map[string]bool)
+	for i, v := range values {
+		constName := goName + strcase.UpperCamelCase(nonIdentifierChars.ReplaceAllString(v, "-"))
+		if constName == goName || used[constName] {
+			constName = goName + "Value" + strconv.Itoa(i)
+		}
+		used[constName] = true
+		en.Values = append(en.Values, &EnumValue{Name: constName, Value: v})
+	}
+	g.Enums[goName] = en
+	return en
+}
+
+// RenderEnums writes the enum types of all the resources in a single file of the api package
+func (g *Generator) RenderEnums() error {
+	f := g.createGoFile(filepath.Join(g.Config.OutputDir, "api", g.Config.Version, enumFileName), nil)
+	defer f.Close()
+
+	names := make([]string, 0, len(g.Enums))
+	for name := range g.Enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	enums := make([]*Enum, 0, len(names))
+	for _, name := range names {
+		enums = append(enums, g.Enums[name])
+	}
+
+	s := struct {
+		Version string
+		Enums   []*Enum
+	}{
+		Version: g.Config.Version,
+		Enums:   enums,
+	}
+	if err := g.executeTemplate(f, "enum"+".tmpl", s); err != nil {
+		g.log.Debug("Write enum error", "error", err)
+		return err
+	}
+	return f.Close()
+}
+
+// uniqueStrings returns the strings without duplicates, keeping the order
+func uniqueStrings(ss []string) []string {
+	seen := make(map[string]bool)
+	u := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if !seen[s] {
+			seen[s] = true
+			u = append(u, s)
+		}
+	}
+	return u
+}
+
+// equalStrings returns true when both string slices hold the same strings in the same order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
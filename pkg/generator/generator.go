@@ -18,6 +18,7 @@ package generator
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -30,9 +31,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-ygen/pkg/templ"
+	"github.com/yndd/ndd-yang/pkg/container"
 	"github.com/yndd/ndd-yang/pkg/parser"
 	"github.com/yndd/ndd-yang/pkg/resource"
-	"github.com/netw-device-driver/ndd-ygen/pkg/templ"
 	"gopkg.in/yaml.v2"
 )
 
@@ -49,12 +51,36 @@ type Generator struct {
 	parser *parser.Parser
 	Config *GeneratorConfig // holds the configuration for the generator
 	//ResourceConfig  map[string]*ResourceDetails // holds the configuration of the resources we should generate
-	Resources   []*resource.Resource // holds the resources that are being generated
-	Entries     []*yang.Entry        // Yang entries parsed from the yang files
-	Template    *template.Template
-	log         logging.Logger
-	LocalRender bool
-	Debug       bool
+	Resources []*resource.Resource               // holds the resources that are being generated
+	States    map[*resource.Resource]*State      // holds the state trees of the resources that are being generated
+	Choices   map[*container.Container][]*Choice // holds the yang choices of the containers that are being generated
+	LeafLists map[*container.Entry]*LeafList     // holds the leaf-list constraints of the container entries
+	Enums     map[string]*Enum                   // holds the enum types of the resources by go type name
+	Rules     map[*container.Container][]*Rule   // holds the validation rules of the containers
+	// holds the must and when statements which cannot be translated into validation rules
+	Untranslated []*UntranslatedStatement
+	statements   []*statement                     // holds the must and when statements which are translated after the run
+	yangEntries  map[*container.Entry]*yang.Entry // maps the container entries to their yang entry
+	// maps the containers to the yang entry of the container or list
+	yangContainers map[*container.Container]*yang.Entry
+	Entries        []*yang.Entry          // Yang entries parsed from the yang files
+	diagnostics    []*Diagnostic          // the errors of processing the yang modules
+	resourceMap    map[string]PathDetails // the paths of the resource map input file
+	Template       *template.Template
+	output         Output                                // where the generated files are written to
+	resourceFiles  map[*resource.Resource]io.WriteCloser // the files of the api types of the resources
+	log            logging.Logger
+	LocalRender    bool
+	TemplateDir    string // the directory with templates that replace the default templates
+	Debug          bool
+	Strict         bool // fail on the errors of processing the yang modules
+	// the problems of the resource map are reported by Validate instead of failing the generator
+	ValidateOnly bool
+
+	// the details of the resources in the resource map input file
+	resourceDetails map[*resource.Resource]*PathDetails
+	typeNames       map[*container.Container]string // the go type names that override the default names
+	fieldNames      map[*container.Entry]string     // the go field names that override the default names
 }
 
 type GeneratorConfig struct {
@@ -67,17 +93,38 @@ type GeneratorConfig struct {
 	Version              string // the version of the api we generate for k8s
 	ApiGroup             string // the apigroup we generate for k8s
 	Prefix               string // the prefix that is addded to the k8s resource api
+	Module               string // the go module of the provider the code is generated for
+	OutputFormat         string // the output format the resources are rendered in
 }
 
+// the versions of the resource map input file, the overrides of the resources
+// require version v2
+const (
+	resourceMapV1 = "v1"
+	resourceMapV2 = "v2"
+)
+
 // ResourceYamlInput struct
 type ResourceYamlInput struct {
-	Path map[string]PathDetails `yaml:"path"`
+	Version string                 `yaml:"version"`
+	Path    map[string]PathDetails `yaml:"path"`
 }
 
 // PathDetails struct
 type PathDetails struct {
 	Excludes  []string               `yaml:"excludes"`
 	Hierarchy map[string]PathDetails `yaml:"hierarchy"`
+
+	// the overrides of the generated kubernetes resource
+	Kind         string             `yaml:"kind"`
+	ShortNames   []string           `yaml:"shortNames"`
+	Categories   []string           `yaml:"categories"`
+	Scope        string             `yaml:"scope"`
+	PrintColumns []CrdPrinterColumn `yaml:"printColumns"`
+	// the go type names of the containers and the go field names of the entries
+	// by their path, the path starts with the last element of the resource path
+	TypeNames  map[string]string `yaml:"typeNames"`
+	FieldNames map[string]string `yaml:"fieldNames"`
 }
 
 // Option can be used to manipulate Options.
@@ -143,31 +190,85 @@ func WithPrefix(s string) Option {
 	}
 }
 
+func WithModule(s string) Option {
+	return func(g *Generator) {
+		g.Config.Module = s
+	}
+}
+
+func WithOutputFormat(s string) Option {
+	return func(g *Generator) {
+		g.Config.OutputFormat = s
+	}
+}
+
 func WithLocalRender(b bool) Option {
 	return func(g *Generator) {
 		g.LocalRender = b
 	}
 }
 
+func WithOutput(o Output) Option {
+	return func(g *Generator) {
+		g.output = o
+	}
+}
+
+func WithStrict(b bool) Option {
+	return func(g *Generator) {
+		g.Strict = b
+	}
+}
+
+func WithValidateOnly(b bool) Option {
+	return func(g *Generator) {
+		g.ValidateOnly = b
+	}
+}
+
+func WithTemplateDir(s string) Option {
+	return func(g *Generator) {
+		g.TemplateDir = s
+	}
+}
+
 // NewYangGoCodeGenerator function defines a new generator
 func NewGenerator(opts ...Option) (*Generator, error) {
 	g := &Generator{
 		parser: parser.NewParser(),
-		Config: new(GeneratorConfig),
+		Config: &GeneratorConfig{OutputFormat: OutputFormatK8s},
 		//ResourceConfig:  make(map[string]*ResourceDetails),
 		Resources: make([]*resource.Resource, 0),
+		States:    make(map[*resource.Resource]*State),
+		Choices:   make(map[*container.Container][]*Choice),
+		LeafLists: make(map[*container.Entry]*LeafList),
+		Enums:     make(map[string]*Enum),
+		Rules:     make(map[*container.Container][]*Rule),
+
+		yangEntries:    make(map[*container.Entry]*yang.Entry),
+		yangContainers: make(map[*container.Container]*yang.Entry),
+		output:         diskOutput{},
+		resourceFiles:  make(map[*resource.Resource]io.WriteCloser),
+
+		resourceDetails: make(map[*resource.Resource]*PathDetails),
+		typeNames:       make(map[*container.Container]string),
+		fieldNames:      make(map[*container.Entry]string),
 	}
 
 	for _, o := range opts {
 		o(g)
 	}
 
+	if _, ok := renderers[g.Config.OutputFormat]; !ok {
+		return nil, errors.Errorf("%s: %s", errUnknownOutputFormat, g.Config.OutputFormat)
+	}
+
 	// process templates to render the resources
 	if g.LocalRender {
 		var err error
-		g.Template, err = templ.ParseTemplates("./templates/")
+		g.Template, err = templ.ParseTemplates(g.TemplateDir)
 		if err != nil {
-			return nil, errors.New(errParseTemplate)
+			return nil, errors.Wrap(err, errParseTemplate)
 		}
 	}
 
@@ -187,6 +288,15 @@ func NewGenerator(opts ...Option) (*Generator, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, errResourceInputFileUnMarshal)
 	}
+	// a misspelled override of a v2 resource map would silently not be applied
+	if c.Version == resourceMapV2 {
+		if err := yaml.UnmarshalStrict(yamlFile, new(ResourceYamlInput)); err != nil {
+			return nil, errors.Wrap(err, errResourceInputFileUnMarshal)
+		}
+	}
+	if err := validateResourceMapVersion(c); err != nil {
+		return nil, err
+	}
 
 	// initialize the resources from the YAML input file, we start at the root level using "/" path
 	if err := g.InitializeResourcesNew(c.Path, "/", 1); err != nil {
@@ -200,6 +310,18 @@ func NewGenerator(opts ...Option) (*Generator, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the errors of processing yang result in missing or partial types of the resources
+	g.affectedResources()
+	if !g.ValidateOnly && len(g.diagnostics) > 0 && g.Strict {
+		return nil, diagnosticsError(g.diagnostics)
+	}
+	// a path in the resource map that does not exist in yang would silently not be generated
+	g.resourceMap = c.Path
+	if !g.ValidateOnly {
+		if err := g.ValidateResourceMap(c.Path); err != nil {
+			return nil, err
+		}
+	}
 
 	return g, nil
 }
@@ -234,7 +356,14 @@ func (g *Generator) FindResource(p string) (*resource.Resource, error) {
 // A resource contains the relative information of the resource.
 // To DependsOn allows you to reference parent resources
 func (g *Generator) InitializeResourcesNew(pd map[string]PathDetails, pp string, offset int) error {
-	for path, pathdetails := range pd {
+	// the paths are sorted to initialize the resources in the same order on every run
+	paths := make([]string, 0, len(pd))
+	for path := range pd {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathdetails := pd[path]
 		//g.log.Debug("Path information", "Path", path, "parent path", pp)
 		opts := []resource.Option{
 			resource.WithXPath(path),
@@ -256,7 +385,10 @@ func (g *Generator) InitializeResourcesNew(pd map[string]PathDetails, pp string,
 
 		// initialize the resource before processing the next hierarchy since the process will check
 		// the dependency and if not initialized the parent resource will not be found.
-		g.Resources = append(g.Resources, resource.NewResource(opts...))
+		r := resource.NewResource(opts...)
+		g.Resources = append(g.Resources, r)
+		details := pathdetails
+		g.resourceDetails[r] = &details
 		if pathdetails.Hierarchy != nil {
 			// run the procedure in a hierarchical way, offset is 0 since the resource does not have
 			// a duplicate element in the path
@@ -318,12 +450,7 @@ func (g *Generator) InitializeGoYang() ([]*yang.Entry, error) {
 	}
 
 	// Process the yang modules
-	errs := ms.Process()
-	if len(errs) > 0 {
-		for err := range errs {
-			g.log.Debug("Error", "error", err)
-		}
-	}
+	g.diagnostics = newDiagnostics(ms, ms.Process())
 	// Keep track of the top level modules we read in.
 	// Those are the only modules we want to process.
 	mods := map[string]*yang.Module{}
@@ -348,12 +475,14 @@ func (g *Generator) Run() error {
 		//g.log.Debug("Yang global Entry: ", "Nbr", i, "Name", e.Name)
 
 		// initialize an empty path
-		path := config.Path{
+		path := &config.Path{
 			Elem: make([]*config.PathElem, 0),
 		}
 		if err := g.ResourceGenerator("", path, e); err != nil {
 			return err
 		}
 	}
-	return nil
+	// the statements are translated when all the containers of the resources exist
+	g.TranslateStatements()
+	return g.applyNameOverrides()
 }
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"bytes"
+	"go/scanner"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yndd/ndd-yang/pkg/resource"
+	"golang.org/x/tools/imports"
+)
+
+const (
+	errGoFormat = "cannot format generated go file"
+)
+
+// goFile is a generated go file which is rendered in memory from one or more templates,
+// the file is formatted and its imports are fixed when it is closed
+type goFile struct {
+	bytes.Buffer
+	output   Output
+	fileName string
+	resource string // the resource the templates are currently rendered for
+	sections []goFileSection
+	closed   bool
+	err      error
+}
+
+// goFileSection is the part of a generated go file which is rendered from a template
+type goFileSection struct {
+	line     int // the first line of the section
+	template string
+	resource string
+}
+
+// createGoFile returns a generated go file of the resource, the resource is nil for the
+// files which are rendered for all resources
+func (g *Generator) createGoFile(fileName string, r *resource.Resource) *goFile {
+	f := &goFile{output: g.output, fileName: fileName}
+	f.forResource(r)
+	return f
+}
+
+// forResource sets the resource the next templates are rendered for
+func (f *goFile) forResource(r *resource.Resource) {
+	f.resource = ""
+	if r != nil {
+		f.resource = *r.GetAbsoluteXPath()
+	}
+}
+
+// executeTemplate executes the template, the sections of a generated go file are
+// recorded to point at the template of a line which fails to format
+func (g *Generator) executeTemplate(w io.Writer, name string, data interface{}) error {
+	if f, ok := w.(*goFile); ok {
+		f.sections = append(f.sections, goFileSection{
+			line:     bytes.Count(f.Bytes(), []byte("\n")) + 1,
+			template: name,
+			resource: f.resource,
+		})
+	}
+	return g.Template.ExecuteTemplate(w, name, data)
+}
+
+// Close formats the go file, removes the unused imports and adds the missing imports
+// before it is written
+func (f *goFile) Close() error {
+	if f.closed {
+		return f.err
+	}
+	f.closed = true
+	b, err := imports.Process(f.fileName, f.Bytes(), &imports.Options{Comments: true, TabIndent: true, TabWidth: 8})
+	if err != nil {
+		f.err = f.formatError(err)
+		return f.err
+	}
+	f.err = f.output.WriteFile(f.fileName, b)
+	return f.err
+}
+
+// formatError returns the error of formatting the go file with the template, the
+// resource and the source of the first line which cannot be parsed
+func (f *goFile) formatError(err error) error {
+	var errs scanner.ErrorList
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return errors.Wrapf(err, "%s %s", errGoFormat, f.fileName)
+	}
+	line := errs[0].Pos.Line
+	var section *goFileSection
+	for i := range f.sections {
+		if f.sections[i].line <= line {
+			section = &f.sections[i]
+		}
+	}
+	if lines := strings.Split(f.String(), "\n"); line > 0 && line <= len(lines) {
+		err = errors.Errorf("%s: %q", err, strings.TrimSpace(lines[line-1]))
+	}
+	switch {
+	case section == nil:
+		return errors.Wrapf(err, "%s %s", errGoFormat, f.fileName)
+	case section.resource == "":
+		return errors.Wrapf(err, "%s %s, rendered from template %s", errGoFormat, f.fileName, section.template)
+	default:
+		return errors.Wrapf(err, "%s %s, rendered from template %s for resource %s", errGoFormat, f.fileName, section.template, section.resource)
+	}
+}
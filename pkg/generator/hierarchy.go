@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"strings"
+
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+// HierarchyKey is a key of a hierarchical parent of a resource, the parameters of the
+// resource hold a field per key of its parents
+type HierarchyKey struct {
+	Name     string // the name of the root container of the parent resource
+	Key      string // the name of the key
+	Type     string // the go type of the key
+	Field    string // the name of the field in the parameters
+	JSONName string // the json name of the field in the parameters
+}
+
+// hierarchyKeys returns the keys of the hierarchical parents of resource r, starting
+// with the keys of the direct parent
+func (g *Generator) hierarchyKeys(r *resource.Resource) []*HierarchyKey {
+	keys := make([]*HierarchyKey, 0)
+	for p := r.DependsOn; p != nil; p = p.DependsOn {
+		if p.RootContainerEntry == nil {
+			continue
+		}
+		name := p.RootContainerEntry.Name
+		for _, k := range strings.Fields(p.RootContainerEntry.Key) {
+			keys = append(keys, &HierarchyKey{
+				Name:     name,
+				Key:      k,
+				Type:     g.hierarchyKeyType(p, k),
+				Field:    g.hierarchyKeyField(p, k),
+				JSONName: strcase.KebabCase(name) + "-" + strcase.KebabCase(k),
+			})
+		}
+	}
+	return keys
+}
+
+// hierarchyKeyField returns the name of the field of key k of hierarchical parent p in
+// the parameters of a resource
+func (g *Generator) hierarchyKeyField(p *resource.Resource, k string) string {
+	return strcase.UpperCamelCase(g.Config.Prefix) +
+		strcase.UpperCamelCase(p.RootContainerEntry.Name) +
+		strcase.UpperCamelCase(k)
+}
+
+// hierarchyKeyType returns the go type of key k of the root container of resource p,
+// the same way the parser types the key of a list with a single key
+func (g *Generator) hierarchyKeyType(p *resource.Resource, k string) string {
+	e, ok := g.yangEntries[p.RootContainerEntry]
+	if !ok || e.Dir[k] == nil {
+		return p.RootContainerEntry.Type
+	}
+	switch t := g.parser.GetTypeName(e.Dir[k]); t {
+	case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64":
+		return t
+	case "boolean":
+		return "bool"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pkg/errors"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const (
+	// OutputFormatJSONSchema renders the json schemas of the resources
+	OutputFormatJSONSchema = "jsonschema"
+
+	jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+	errJSONSchemaMarshal = "cannot marshal json schema"
+	errJSONSchemaWrite   = "cannot write json schema"
+)
+
+// JSONSchema is a json schema document or subschema
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Minimum              *int64                 `json:"minimum,omitempty"`
+	Maximum              *int64                 `json:"maximum,omitempty"`
+	MinLength            *int64                 `json:"minLength,omitempty"`
+	MaxLength            *int64                 `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	MinItems             *uint64                `json:"minItems,omitempty"`
+	MaxItems             *uint64                `json:"maxItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// jsonSchemaBuilder builds the json schema of a resource, it keeps the owner of
+// every definition such that containers, enums and typedefs with the same name get
+// their own definition
+type jsonSchemaBuilder struct {
+	g      *Generator
+	defs   map[string]*JSONSchema
+	owners map[string]interface{}
+}
+
+// def returns the name of the definition of the owner and true when it is already
+// defined, a definition name that is used by another owner gets a numbered name
+func (b *jsonSchemaBuilder) def(name string, owner interface{}) (string, bool) {
+	defName := name
+	for i := 2; ; i++ {
+		o, ok := b.owners[defName]
+		if !ok {
+			b.owners[defName] = owner
+			return defName, false
+		}
+		if o == owner {
+			return defName, true
+		}
+		defName = name + strconv.Itoa(i)
+	}
+}
+
+// RenderJSONSchemas writes a json schema per resource, which validates the parameters
+// of the resource as they are specified for a network node
+func (g *Generator) RenderJSONSchemas() error {
+	dir := filepath.Join(g.Config.OutputDir, "jsonschema")
+	if err := g.output.MkdirAll(dir); err != nil {
+		return err
+	}
+	for _, r := range g.Resources {
+		b, err := json.MarshalIndent(g.BuildJSONSchema(r), "", "  ")
+		if err != nil {
+			return errors.Wrap(err, errJSONSchemaMarshal)
+		}
+		fileName := filepath.Join(dir, g.Config.Prefix+"-"+strcase.KebabCase(r.GetAbsoluteName())+".schema.json")
+		if err := g.output.WriteFile(fileName, append(b, '\n')); err != nil {
+			return errors.Wrap(err, errJSONSchemaWrite)
+		}
+	}
+	return nil
+}
+
+// BuildJSONSchema builds the json schema of a resource, the containers, enums and
+// typedefs of the resource are defined once in the $defs of the schema
+func (g *Generator) BuildJSONSchema(r *resource.Resource) *JSONSchema {
+	kind := g.kind(r)
+	resourceName := strcase.KebabCase(r.GetResourceNameWithPrefix(""))
+	b := &jsonSchemaBuilder{
+		g:      g,
+		defs:   map[string]*JSONSchema{},
+		owners: map[string]interface{}{},
+	}
+	s := &JSONSchema{
+		Schema:               jsonSchemaDialect,
+		ID:                   "https://" + g.Config.ApiGroup + "/" + g.Config.Version + "/" + strings.ToLower(kind) + ".schema.json",
+		Title:                kind,
+		Description:          "the parameters of the " + kind + " for a network node",
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: jsonSchemaBool(false),
+		Defs:                 b.defs,
+	}
+	// the parameters hold the keys of the hierarchical resources and the root container
+	for _, k := range b.g.hierarchyKeys(r) {
+		s.Properties[k.JSONName] = b.leaf(&container.Entry{Type: k.Type})
+		s.Required = append(s.Required, k.JSONName)
+	}
+	key := ""
+	if r.RootContainerEntry != nil {
+		key = r.RootContainerEntry.Key
+	}
+	s.Properties[resourceName] = b.container(r.Container, key)
+	s.Required = append(s.Required, resourceName)
+	return s
+}
+
+// container returns a reference to the definition of the container, the keys of a
+// list container are required
+func (b *jsonSchemaBuilder) container(c *container.Container, key string) *JSONSchema {
+	defName, ok := b.def(strcase.UpperCamelCase(c.GetFullName()), c)
+	ref := &JSONSchema{Ref: "#/$defs/" + defName}
+	if ok {
+		return ref
+	}
+	s := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: jsonSchemaBool(false),
+	}
+	b.defs[defName] = s
+	s.Required = append(s.Required, strings.Fields(key)...)
+	for _, e := range b.g.containerEntries(c) {
+		name := strcase.KebabCase(e.Name)
+		switch {
+		case e.LeafList != nil:
+			// leaf-list in the container
+			ls := &JSONSchema{
+				Type:        "array",
+				Items:       b.leaf(e.Entry),
+				UniqueItems: true,
+			}
+			if e.LeafList.MinElements > 0 {
+				min := e.LeafList.MinElements
+				ls.MinItems = &min
+			}
+			if e.LeafList.MaxElements > 0 {
+				max := e.LeafList.MaxElements
+				ls.MaxItems = &max
+			}
+			if e.Default != "" {
+				ls.Default = []interface{}{crdDefault(jsonSchemaType(e.Type), e.Default)}
+			}
+			s.Properties[name] = ls
+			if e.Mandatory || e.LeafList.MinElements > 0 {
+				s.Required = append(s.Required, name)
+			}
+		case e.Next != nil && e.Key != "":
+			// list in the container
+			s.Properties[name] = &JSONSchema{
+				Type:  "array",
+				Items: b.container(e.Next, e.Key),
+			}
+		case e.Next != nil:
+			// container in the container
+			s.Properties[name] = b.container(e.Next, "")
+		default:
+			// regular leaf in the container
+			ls := b.leaf(e.Entry)
+			if e.Default != "" {
+				ls.Default = crdDefault(jsonSchemaType(e.Type), e.Default)
+			}
+			s.Properties[name] = ls
+			if e.Mandatory {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+	s.Required = uniqueStrings(s.Required)
+	return ref
+}
+
+// leaf returns the schema of a leaf, which references the definition of the enum or
+// typedef of the leaf
+func (b *jsonSchemaBuilder) leaf(e *container.Entry) *JSONSchema {
+	if en, ok := b.g.Enums[e.Type]; ok {
+		defName, ok := b.def(en.Name, en)
+		if !ok {
+			values := make([]interface{}, 0, len(en.Values))
+			for _, v := range en.Values {
+				values = append(values, v.Value)
+			}
+			b.defs[defName] = &JSONSchema{
+				Description: "the " + en.Kind + " " + en.YangName,
+				Type:        "string",
+				Enum:        values,
+			}
+		}
+		return &JSONSchema{Ref: "#/$defs/" + defName}
+	}
+
+	s := &JSONSchema{Type: jsonSchemaType(e.Type)}
+	switch e.Type {
+	case "int8", "int16", "int32", "uint8", "uint16":
+		s.Format = "int32"
+	case "int64", "uint32", "uint64":
+		s.Format = "int64"
+	}
+	if s.Type == "integer" {
+		s.Minimum, s.Maximum = b.g.rangeBounds(e)
+	}
+	if s.Type == "string" {
+		s.MinLength, s.MaxLength = b.g.lengthBounds(e)
+	}
+	if s.Type == "string" {
+		s.Pattern = enforceablePattern(e)
+	}
+	for _, enum := range e.Enum {
+		s.Enum = append(s.Enum, enum)
+	}
+
+	ye, ok := b.g.yangEntries[e]
+	if !ok || ye.Type == nil {
+		return s
+	}
+	if _, ok := yang.BaseTypedefs[ye.Type.Name]; ok {
+		return s
+	}
+	// leaves of the same typedef share the definition when they have the same
+	// restrictions, the restrictions of a leaf can refine the typedef
+	d, err := json.Marshal(s)
+	if err != nil {
+		return s
+	}
+	defName, ok := b.def(strcase.UpperCamelCase(nonIdentifierChars.ReplaceAllString(ye.Type.Name, "-")), ye.Type.Name+string(d))
+	if !ok {
+		b.defs[defName] = s
+	}
+	return &JSONSchema{Ref: "#/$defs/" + defName}
+}
+
+// jsonSchemaType returns the json type of a go type
+func jsonSchemaType(t string) string {
+	switch t {
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		return "integer"
+	case "float32", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	}
+	return "string"
+}
+
+// jsonSchemaBool returns a pointer to the bool
+func jsonSchemaBool(b bool) *bool {
+	return &b
+}
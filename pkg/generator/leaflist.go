@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"math"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/yndd/ndd-yang/pkg/container"
+)
+
+// LeafList holds the element constraints of a yang leaf-list
+type LeafList struct {
+	MinElements uint64
+	MaxElements uint64 // 0 when the number of elements is unbounded
+}
+
+// ContainerEntry is a container entry as it is rendered in the templates, which
+// extends the entry with the leaf-list information and description of the yang entry
+type ContainerEntry struct {
+	*container.Entry
+	FieldName   string // the name of the go field of the entry
+	LeafList    *LeafList
+	Description string
+	Comment     []string // the description wrapped in the lines of a go comment
+}
+
+// addLeafList records the container entry ce as a leaf-list when the yang entry is a leaf-list
+func (g *Generator) addLeafList(e *yang.Entry, ce *container.Entry) {
+	if !e.IsLeafList() {
+		return
+	}
+	ll := &LeafList{
+		MinElements: e.ListAttr.MinElements,
+		MaxElements: e.ListAttr.MaxElements,
+	}
+	if ll.MaxElements == math.MaxUint64 {
+		ll.MaxElements = 0
+	}
+	g.LeafLists[ce] = ll
+}
+
+// containerEntries returns the entries of the container as they are rendered in the templates
+func (g *Generator) containerEntries(c *container.Container) []*ContainerEntry {
+	entries := make([]*ContainerEntry, 0, len(c.Entries))
+	for _, e := range c.Entries {
+		description := yangDescription(g.yangEntries[e])
+		entries = append(entries, &ContainerEntry{
+			Entry:       e,
+			FieldName:   g.goFieldName(e),
+			LeafList:    g.LeafLists[e],
+			Description: description,
+			Comment:     commentLines(description),
+		})
+	}
+	return entries
+}
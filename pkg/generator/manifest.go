@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	manifestFileName = ".ndd-ygen-manifest.yaml"
+
+	errManifestRead      = "cannot read manifest"
+	errManifestUnMarshal = "cannot unmarshal manifest"
+	errManifestMarshal   = "cannot marshal manifest"
+)
+
+// Manifest holds the files which are generated in the output directory per output
+// format, such that the files of the resources which are removed from the resource map
+// are deleted by the next generation
+type Manifest struct {
+	// the generated files by output format, relative to the output directory
+	Files map[string][]string `yaml:"files"`
+}
+
+// updateManifest removes the files of the previous generation of the output format
+// which are no longer generated and adds the manifest to the staged files
+func (g *Generator) updateManifest(staged *MemoryOutput) error {
+	fileName := filepath.Join(g.Config.OutputDir, manifestFileName)
+	manifest := &Manifest{}
+	b, err := staged.ReadFile(fileName)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(b, manifest); err != nil {
+			return errors.Wrap(err, errManifestUnMarshal)
+		}
+	case !os.IsNotExist(err):
+		return errors.Wrap(err, errManifestRead)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string][]string)
+	}
+
+	generated := make(map[string]bool)
+	files := make([]string, 0, len(staged.files))
+	for name := range staged.files {
+		rel, err := filepath.Rel(g.Config.OutputDir, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		generated[rel] = true
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+
+	for _, rel := range manifest.Files[g.Config.OutputFormat] {
+		if !generated[rel] {
+			if err := staged.Remove(filepath.Join(g.Config.OutputDir, filepath.FromSlash(rel))); err != nil {
+				return err
+			}
+		}
+	}
+	manifest.Files[g.Config.OutputFormat] = files
+
+	b, err = yaml.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, errManifestMarshal)
+	}
+	return staged.WriteFile(fileName, b)
+}
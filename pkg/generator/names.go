@@ -0,0 +1,236 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const (
+	scopeCluster    = "Cluster"
+	scopeNamespaced = "Namespaced"
+)
+
+// kind returns the kubernetes kind of the resource
+func (g *Generator) kind(r *resource.Resource) string {
+	if d, ok := g.resourceDetails[r]; ok && d.Kind != "" {
+		return d.Kind
+	}
+	return r.GetResourceNameWithPrefix(g.Config.Prefix)
+}
+
+// shortNames returns the kubernetes short names of the resource
+func (g *Generator) shortNames(r *resource.Resource) []string {
+	if d, ok := g.resourceDetails[r]; ok {
+		return d.ShortNames
+	}
+	return nil
+}
+
+// categories returns the kubernetes categories of the resource
+func (g *Generator) categories(r *resource.Resource) []string {
+	if d, ok := g.resourceDetails[r]; ok && len(d.Categories) > 0 {
+		return d.Categories
+	}
+	return []string{"ndd", g.Config.Prefix}
+}
+
+// scope returns the kubernetes scope of the resource
+func (g *Generator) scope(r *resource.Resource) string {
+	if d, ok := g.resourceDetails[r]; ok && d.Scope != "" {
+		return d.Scope
+	}
+	return scopeCluster
+}
+
+// printColumns returns the printer columns of the resource, the columns of the resource
+// map are added before the age of the resource
+func (g *Generator) printColumns(r *resource.Resource) []CrdPrinterColumn {
+	defaults := crdPrinterColumns()
+	d, ok := g.resourceDetails[r]
+	if !ok || len(d.PrintColumns) == 0 {
+		return defaults
+	}
+	columns := make([]CrdPrinterColumn, 0, len(defaults)+len(d.PrintColumns))
+	columns = append(columns, defaults[:len(defaults)-1]...)
+	columns = append(columns, d.PrintColumns...)
+	return append(columns, defaults[len(defaults)-1])
+}
+
+// goTypeName returns the go type name of the container
+func (g *Generator) goTypeName(c *container.Container) string {
+	if name, ok := g.typeNames[c]; ok {
+		return name
+	}
+	return strcase.UpperCamelCase(c.GetFullName())
+}
+
+// goFieldName returns the go field name of the container entry
+func (g *Generator) goFieldName(e *container.Entry) string {
+	if name, ok := g.fieldNames[e]; ok {
+		return name
+	}
+	return strcase.UpperCamelCase(e.Name)
+}
+
+// goFieldNames returns the go field names of the entries of the container
+func (g *Generator) goFieldNames(c *container.Container) map[*container.Entry]string {
+	names := make(map[*container.Entry]string, len(c.Entries))
+	for _, e := range c.Entries {
+		names[e] = g.goFieldName(e)
+	}
+	return names
+}
+
+// rootTypeName returns the go type name of the root container of the resource
+func (g *Generator) rootTypeName(r *resource.Resource) string {
+	if r.Container == nil {
+		return strcase.UpperCamelCase(r.ResourceLastElement())
+	}
+	return g.goTypeName(r.Container)
+}
+
+// applyNameOverrides applies the go type and field names of the resource map to the
+// containers of the resources, the entries that refer to a renamed container get the
+// new type name. The names are only applied when they do not collide with the other
+// go types of the api package or the other fields of their container.
+func (g *Generator) applyNameOverrides() error {
+	typeNames := make(map[*container.Container]string)
+	fieldNames := make(map[*container.Entry]string)
+	for _, r := range g.Resources {
+		d, ok := g.resourceDetails[r]
+		if !ok || (len(d.TypeNames) == 0 && len(d.FieldNames) == 0) {
+			continue
+		}
+		typeNamesByPath := overridesByPath(d.TypeNames)
+		fieldNamesByPath := overridesByPath(d.FieldNames)
+		for _, c := range r.ContainerList {
+			path := containerPath(c)
+			if name, ok := typeNamesByPath[path]; ok {
+				typeNames[c] = name
+			}
+			for _, e := range c.Entries {
+				if name, ok := fieldNamesByPath[path+"/"+e.Name]; ok {
+					fieldNames[e] = name
+				}
+			}
+		}
+	}
+	if problems := g.nameOverrideCollisions(typeNames, fieldNames); len(problems) > 0 {
+		return errors.Errorf("%s: %s", errInvalidResourceMap, strings.Join(problems, "; "))
+	}
+
+	for c, name := range typeNames {
+		g.typeNames[c] = name
+		if c.Prev != nil {
+			for _, e := range c.Prev.Entries {
+				if e.Next == c {
+					e.Type = name
+				}
+			}
+		}
+	}
+	for e, name := range fieldNames {
+		g.fieldNames[e] = name
+	}
+	return nil
+}
+
+// nameOverrideCollisions returns the problems of the type names which collide with an
+// enum, a type of a kind, the type of another container or another type name, and of
+// the field names which collide with another field of their container
+func (g *Generator) nameOverrideCollisions(typeNames map[*container.Container]string, fieldNames map[*container.Entry]string) []string {
+	// the go types of the api package by name, with their description
+	types := make(map[string]string)
+	for name := range g.Enums {
+		types[name] = "enum " + name
+	}
+	for _, r := range g.Resources {
+		kind := g.kind(r)
+		for _, suffix := range []string{"", "List", "Spec", "Status", "Parameters", "Observation"} {
+			types[kind+suffix] = "type " + kind + suffix + " of kind " + kind
+		}
+		containers := append([]*container.Container{}, r.ContainerList...)
+		if st, ok := g.States[r]; ok {
+			containers = append(containers, st.ContainerList...)
+		}
+		for _, c := range containers {
+			if _, ok := typeNames[c]; !ok {
+				types[g.goTypeName(c)] = "type of container " + containerPath(c)
+			}
+		}
+	}
+
+	problems := make([]string, 0)
+	for _, r := range g.Resources {
+		for _, c := range r.ContainerList {
+			name, ok := typeNames[c]
+			if !ok {
+				continue
+			}
+			if other, ok := types[name]; ok {
+				problems = append(problems, fmt.Sprintf("type name %s of %s in resource path %s collides with the %s", name, containerPath(c), *r.GetAbsoluteXPath(), other))
+				continue
+			}
+			types[name] = "type name of " + containerPath(c)
+		}
+		for _, c := range r.ContainerList {
+			fields := make(map[string]string)
+			for _, e := range c.Entries {
+				if _, ok := fieldNames[e]; !ok {
+					fields[g.goFieldName(e)] = e.Name
+				}
+			}
+			for _, e := range c.Entries {
+				name, ok := fieldNames[e]
+				if !ok {
+					continue
+				}
+				if other, ok := fields[name]; ok {
+					problems = append(problems, fmt.Sprintf("field name %s of %s/%s in resource path %s collides with the field of %s", name, containerPath(c), e.Name, *r.GetAbsoluteXPath(), other))
+					continue
+				}
+				fields[name] = e.Name
+			}
+		}
+	}
+	return problems
+}
+
+// overridesByPath returns the overrides by their path without keys and module prefixes
+func overridesByPath(overrides map[string]string) map[string]string {
+	m := make(map[string]string, len(overrides))
+	for path, name := range overrides {
+		m["/"+strings.Join(resourcePathElems(path), "/")] = name
+	}
+	return m
+}
+
+// containerPath returns the path of the container relative to the parent of the resource
+func containerPath(c *container.Container) string {
+	path := ""
+	for ; c != nil; c = c.Prev {
+		path = "/" + c.Name + path
+	}
+	return path
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+func TestRenderNameOverrides(t *testing.T) {
+	outputDir := t.TempDir()
+	files := renderProcess(t, OutputFormatK8s, outputDir, "testdata/resourcemap-v2.yaml")
+	types := string(files[filepath.Join(outputDir, "api", "v1", "srl-interface_types.go")])
+	if types == "" {
+		t.Fatalf("the types of the interface are not rendered")
+	}
+	for _, want := range []string{
+		// the kind and its kubernetes resource
+		"type SrlIf struct {",
+		"type SrlIfList struct {",
+		"// +kubebuilder:resource:scope=Namespaced,categories={ndd,interfaces},shortName={srlif}",
+		`// +kubebuilder:printcolumn:name="MTU",type="integer",JSONPath=".spec.forNetworkNode.interface.mtu"`,
+		// the type and field names
+		"type Eth struct {",
+		"Ethernet    *Eth    `json:\"ethernet,omitempty\"`",
+		"MTU *uint16 `json:\"mtu,omitempty\"`",
+	} {
+		if !strings.Contains(types, want) {
+			t.Errorf("the types of the interface do not contain %q", want)
+		}
+	}
+	for _, notWant := range []string{"type SrlInterface struct {", "type InterfaceEthernet struct {", "Mtu *uint16"} {
+		if strings.Contains(types, notWant) {
+			t.Errorf("the types of the interface contain the default name %q", notWant)
+		}
+	}
+}
+
+var (
+	runFixtureOnce sync.Once
+	runFixtureErr  error
+)
+
+// newRunFixture returns the generator of the fixture resource map with its resources
+func newRunFixture(t *testing.T) *Generator {
+	t.Helper()
+	g := newValidateFixture(t)
+	runFixtureOnce.Do(func() {
+		runFixtureErr = g.Run()
+	})
+	if runFixtureErr != nil {
+		t.Fatalf("Run(): %v", runFixtureErr)
+	}
+	return g
+}
+
+func TestNameOverrideCollisions(t *testing.T) {
+	g := newRunFixture(t)
+	var r *resource.Resource
+	for _, res := range g.Resources {
+		if *res.GetAbsoluteXPath() == "/srl_nokia-interfaces/interface" {
+			r = res
+		}
+	}
+	if r == nil {
+		t.Fatalf("the fixture has no interface resource")
+	}
+
+	cases := map[string]struct {
+		d    *PathDetails
+		want string
+	}{
+		"Enum": {
+			d:    &PathDetails{TypeNames: map[string]string{"/interface/ethernet": "AdminState"}},
+			want: "type name AdminState of /interface/ethernet in resource path /srl_nokia-interfaces/interface collides with the enum AdminState",
+		},
+		"Container": {
+			d:    &PathDetails{TypeNames: map[string]string{"/interface/ethernet": "InterfaceLag"}},
+			want: "type name InterfaceLag of /interface/ethernet in resource path /srl_nokia-interfaces/interface collides with the type of container /interface/lag",
+		},
+		"Kind": {
+			d:    &PathDetails{TypeNames: map[string]string{"/interface/ethernet": "SrlInterfaceSpec"}},
+			want: "collides with the type SrlInterfaceSpec of kind SrlInterface",
+		},
+		"TypeName": {
+			d:    &PathDetails{TypeNames: map[string]string{"/interface/ethernet": "Eth", "/interface/lag": "Eth"}},
+			want: "collides with the type name of /interface/",
+		},
+		"Field": {
+			d:    &PathDetails{FieldNames: map[string]string{"/interface/mtu": "Description"}},
+			want: "field name Description of /interface/mtu in resource path /srl_nokia-interfaces/interface collides with the field of description",
+		},
+	}
+	defer func(d *PathDetails) { g.resourceDetails[r] = d }(g.resourceDetails[r])
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g.resourceDetails[r] = tc.d
+			err := g.applyNameOverrides()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("applyNameOverrides() error = %v, want %q", err, tc.want)
+			}
+			if len(g.typeNames) != 0 || len(g.fieldNames) != 0 {
+				t.Errorf("applyNameOverrides() applies the names when they collide")
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	errOutsideOutputDir = "generated file is outside of the output directory"
+)
+
+// Output is where the generated files are written to
+type Output interface {
+	MkdirAll(dir string) error
+	Create(fileName string) (io.WriteCloser, error)
+	WriteFile(fileName string, b []byte) error
+	// ReadFile reads a file which is written by a previous generation, like a lock file
+	ReadFile(fileName string) ([]byte, error)
+	Remove(fileName string) error
+}
+
+// diskOutput writes the generated files to disk
+type diskOutput struct{}
+
+func (o diskOutput) MkdirAll(dir string) error {
+	return os.MkdirAll(dir, 0755)
+}
+
+func (o diskOutput) Create(fileName string) (io.WriteCloser, error) {
+	return os.Create(fileName)
+}
+
+func (o diskOutput) WriteFile(fileName string, b []byte) error {
+	return ioutil.WriteFile(fileName, b, 0644)
+}
+
+func (o diskOutput) ReadFile(fileName string) ([]byte, error) {
+	return ioutil.ReadFile(fileName)
+}
+
+func (o diskOutput) Remove(fileName string) error {
+	return os.Remove(fileName)
+}
+
+// MemoryOutput keeps the generated files in memory, such that they can be compared with
+// the files on disk. The files which are not generated are read from disk.
+type MemoryOutput struct {
+	files   map[string][]byte
+	removed map[string]bool
+	base    Output // where the files which are not generated are read from
+}
+
+// NewMemoryOutput returns an empty memory output
+func NewMemoryOutput() *MemoryOutput {
+	return newMemoryOutput(diskOutput{})
+}
+
+// newMemoryOutput returns an empty memory output that reads the files which are not
+// generated from the base output
+func newMemoryOutput(base Output) *MemoryOutput {
+	return &MemoryOutput{
+		files:   make(map[string][]byte),
+		removed: make(map[string]bool),
+		base:    base,
+	}
+}
+
+func (o *MemoryOutput) MkdirAll(dir string) error {
+	return nil
+}
+
+func (o *MemoryOutput) Create(fileName string) (io.WriteCloser, error) {
+	return &memoryFile{output: o, fileName: fileName}, nil
+}
+
+func (o *MemoryOutput) WriteFile(fileName string, b []byte) error {
+	o.files[fileName] = append([]byte{}, b...)
+	delete(o.removed, fileName)
+	return nil
+}
+
+func (o *MemoryOutput) ReadFile(fileName string) ([]byte, error) {
+	if b, ok := o.files[fileName]; ok {
+		return b, nil
+	}
+	if o.removed[fileName] {
+		return nil, &os.PathError{Op: "open", Path: fileName, Err: os.ErrNotExist}
+	}
+	return o.base.ReadFile(fileName)
+}
+
+func (o *MemoryOutput) Remove(fileName string) error {
+	delete(o.files, fileName)
+	o.removed[fileName] = true
+	return nil
+}
+
+// Diff writes a unified diff for every generated file that differs from the file on
+// disk, including the files that would be removed. It returns if any file differs.
+func (o *MemoryOutput) Diff(w io.Writer) (bool, error) {
+	fileNames := make([]string, 0, len(o.files)+len(o.removed))
+	for fileName := range o.files {
+		fileNames = append(fileNames, fileName)
+	}
+	for fileName := range o.removed {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	drift := false
+	for _, fileName := range fileNames {
+		current, err := ioutil.ReadFile(fileName)
+		if err != nil && !os.IsNotExist(err) {
+			return drift, err
+		}
+		if o.removed[fileName] && os.IsNotExist(err) {
+			continue
+		}
+		generated := o.files[fileName]
+		if err == nil && bytes.Equal(current, generated) {
+			continue
+		}
+		drift = true
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(current)),
+			B:        difflib.SplitLines(string(generated)),
+			FromFile: fileName,
+			FromDate: "current",
+			ToFile:   fileName,
+			ToDate:   "generated",
+			Context:  3,
+		})
+		if err != nil {
+			return drift, err
+		}
+		if _, err := fmt.Fprint(w, diff); err != nil {
+			return drift, err
+		}
+	}
+	return drift, nil
+}
+
+// rename moves a file, the tests replace it to make moving a file fail
+var rename = os.Rename
+
+// Commit writes the files to the output directory and removes the removed files. The
+// files are staged in a temporary directory in the output directory first and are moved
+// in place when all of them are staged. The files which are replaced or removed are moved
+// to a backup in the temporary directory, which is restored when a file cannot be moved,
+// such that an error does not leave a partially updated output directory behind. The
+// files which did not change are not touched.
+func (o *MemoryOutput) Commit(dir string) (err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	stagingDir, err := ioutil.TempDir(dir, ".ndd-ygen-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+	newDir := filepath.Join(stagingDir, "new")
+	backupDir := filepath.Join(stagingDir, "backup")
+
+	fileNames := make([]string, 0, len(o.files))
+	for fileName := range o.files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	// staged holds the path relative to the output directory of the staged files
+	staged := make(map[string]string)
+	for _, fileName := range fileNames {
+		if current, err := ioutil.ReadFile(fileName); err == nil && bytes.Equal(current, o.files[fileName]) {
+			continue
+		}
+		rel, err := outputRel(dir, fileName)
+		if err != nil {
+			return err
+		}
+		stagedFileName := filepath.Join(newDir, rel)
+		if err := os.MkdirAll(filepath.Dir(stagedFileName), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(stagedFileName, o.files[fileName], 0644); err != nil {
+			return err
+		}
+		staged[fileName] = rel
+	}
+
+	removed := make(map[string]string)
+	removedFileNames := make([]string, 0, len(o.removed))
+	for fileName := range o.removed {
+		rel, err := outputRel(dir, fileName)
+		if err != nil {
+			return err
+		}
+		removed[fileName] = rel
+		removedFileNames = append(removedFileNames, fileName)
+	}
+	sort.Strings(removedFileNames)
+
+	// the files which are moved are restored in the reverse order on an error
+	var replaced []replacement
+	defer func() {
+		if err != nil {
+			for i := len(replaced) - 1; i >= 0; i-- {
+				replaced[i].restore(dir)
+			}
+		}
+	}()
+
+	for _, fileName := range fileNames {
+		rel, ok := staged[fileName]
+		if !ok {
+			continue
+		}
+		r, err := backupFile(fileName, filepath.Join(backupDir, rel))
+		if err != nil {
+			return err
+		}
+		replaced = append(replaced, r)
+		if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+			return err
+		}
+		if err := rename(filepath.Join(newDir, rel), fileName); err != nil {
+			return err
+		}
+	}
+
+	for _, fileName := range removedFileNames {
+		r, err := backupFile(fileName, filepath.Join(backupDir, removed[fileName]))
+		if err != nil {
+			return err
+		}
+		replaced = append(replaced, r)
+	}
+	// the directories which are empty after the removal are removed as well
+	for _, fileName := range removedFileNames {
+		removeEmptyDirs(dir, fileName)
+	}
+	return nil
+}
+
+// outputRel returns the path of the file relative to the output directory
+func outputRel(dir, fileName string) (string, error) {
+	rel, err := filepath.Rel(dir, fileName)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.Errorf("%s: %s", errOutsideOutputDir, fileName)
+	}
+	return rel, nil
+}
+
+// removeEmptyDirs removes the directories of the file up to the output directory which
+// are empty
+func removeEmptyDirs(dir, fileName string) {
+	for d := filepath.Dir(fileName); ; d = filepath.Dir(d) {
+		rel, err := filepath.Rel(dir, d)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") || os.Remove(d) != nil {
+			break
+		}
+	}
+}
+
+// replacement is a file in the output directory that is replaced or removed by a commit,
+// the backup is empty when the file did not exist before the commit
+type replacement struct {
+	fileName string
+	backup   string
+}
+
+// backupFile moves the file to the backup when it exists
+func backupFile(fileName, backup string) (replacement, error) {
+	r := replacement{fileName: fileName}
+	if _, err := os.Lstat(fileName); err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return r, err
+	}
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		return r, err
+	}
+	if err := rename(fileName, backup); err != nil {
+		return r, err
+	}
+	r.backup = backup
+	return r, nil
+}
+
+// restore moves the backup of the file back in place, a file that did not exist before
+// the commit is removed together with the directories which are empty after the removal.
+// Restoring is best effort, the error of the commit is returned.
+func (r replacement) restore(dir string) {
+	os.Remove(r.fileName)
+	if r.backup != "" {
+		rename(r.backup, r.fileName)
+		return
+	}
+	removeEmptyDirs(dir, r.fileName)
+}
+
+// CommitTo writes the files to the output and removes the removed files from it
+func (o *MemoryOutput) CommitTo(output Output) error {
+	fileNames := make([]string, 0, len(o.files))
+	for fileName := range o.files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		if err := output.MkdirAll(filepath.Dir(fileName)); err != nil {
+			return err
+		}
+		if err := output.WriteFile(fileName, o.files[fileName]); err != nil {
+			return err
+		}
+	}
+
+	removed := make([]string, 0, len(o.removed))
+	for fileName := range o.removed {
+		removed = append(removed, fileName)
+	}
+	sort.Strings(removed)
+	for _, fileName := range removed {
+		if err := output.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryFile is a generated file that is kept in the memory output when it is closed
+type memoryFile struct {
+	bytes.Buffer
+	output   *MemoryOutput
+	fileName string
+}
+
+func (f *memoryFile) Close() error {
+	return f.output.WriteFile(f.fileName, f.Bytes())
+}
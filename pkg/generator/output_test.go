@@ -0,0 +1,164 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryOutputCommitTo(t *testing.T) {
+	output := NewMemoryOutput()
+	if err := output.WriteFile("out/lock", []byte("lock")); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.WriteFile("out/old.go", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	staged := newMemoryOutput(output)
+	b, err := staged.ReadFile("out/lock")
+	if err != nil || string(b) != "lock" {
+		t.Fatalf("ReadFile(out/lock) = %q, %v, want the file of the base output", b, err)
+	}
+	if err := staged.WriteFile("out/new.go", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if err := staged.Remove("out/old.go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := staged.ReadFile("out/old.go"); !os.IsNotExist(err) {
+		t.Fatalf("ReadFile(out/old.go) error = %v, want a not exist error", err)
+	}
+
+	if err := staged.CommitTo(output); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(output.files["out/new.go"]); got != "new" {
+		t.Errorf("out/new.go = %q, want %q", got, "new")
+	}
+	if _, ok := output.files["out/old.go"]; ok {
+		t.Errorf("out/old.go is not removed from the output")
+	}
+	if got := string(output.files["out/lock"]); got != "lock" {
+		t.Errorf("out/lock = %q, want %q", got, "lock")
+	}
+}
+
+// readTree returns the content of the files in the directory by their relative path
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			files[rel+"/"] = ""
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		files[rel] = string(b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestMemoryOutputCommit(t *testing.T) {
+	cases := map[string]struct {
+		failRename string
+		want       map[string]string
+	}{
+		"Committed": {
+			want: map[string]string{
+				"a.go":     "new a",
+				"b.go":     "b",
+				"sub/":     "",
+				"sub/d.go": "new d",
+			},
+		},
+		"RenameFails": {
+			failRename: "sub/d.go",
+			want: map[string]string{
+				"a.go":   "a",
+				"b.go":   "b",
+				"c/":     "",
+				"c/c.go": "c",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for fileName, content := range map[string]string{"a.go": "a", "b.go": "b", "c/c.go": "c"} {
+				fileName = filepath.Join(dir, fileName)
+				if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// moving the staged file in place fails once, such that the backups can be restored
+			failRename := ""
+			if tc.failRename != "" {
+				failRename = filepath.Join(dir, tc.failRename)
+			}
+			defer func(r func(string, string) error) { rename = r }(rename)
+			rename = func(oldpath, newpath string) error {
+				if newpath == failRename {
+					failRename = ""
+					return errors.New("rename failed")
+				}
+				return os.Rename(oldpath, newpath)
+			}
+
+			output := NewMemoryOutput()
+			for fileName, content := range map[string]string{"a.go": "new a", "b.go": "b", "sub/d.go": "new d"} {
+				if err := output.WriteFile(filepath.Join(dir, fileName), []byte(content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := output.Remove(filepath.Join(dir, "c/c.go")); err != nil {
+				t.Fatal(err)
+			}
+
+			err := output.Commit(dir)
+			if tc.failRename != "" && err == nil {
+				t.Fatalf("Commit() error = nil, want the rename error")
+			}
+			if tc.failRename == "" && err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+			if got := readTree(t, dir); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("output directory after Commit() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
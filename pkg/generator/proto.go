@@ -0,0 +1,287 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// OutputFormatProto renders the protobuf messages of the resources
+	OutputFormatProto = "proto"
+
+	protoLockFileName = "field-numbers.lock.yaml"
+
+	// the field numbers which are reserved by the protobuf implementation
+	protoReservedFirst = 19000
+	protoReservedLast  = 19999
+
+	errProtoLockRead      = "cannot read protobuf lock file"
+	errProtoLockUnMarshal = "cannot unmarshal protobuf lock file"
+	errProtoLockMarshal   = "cannot marshal protobuf lock file"
+	errProtoLockWrite     = "cannot write protobuf lock file"
+)
+
+// ProtoMessage is the protobuf message of a container
+type ProtoMessage struct {
+	Name          string
+	Fields        []*ProtoField
+	Reserved      []int    // the numbers of the fields which are removed from the message
+	ReservedNames []string // the names of the fields which are removed from the message
+}
+
+// ProtoField is a field of a protobuf message
+type ProtoField struct {
+	Name     string
+	Type     string
+	Number   int
+	Repeated bool
+	Optional bool
+}
+
+// ProtoLock holds the field numbers of the protobuf messages, which keeps the numbers
+// of the fields stable when the messages are regenerated
+type ProtoLock struct {
+	Messages map[string]*ProtoLockMessage `yaml:"messages"`
+}
+
+// ProtoLockMessage holds the field numbers of a protobuf message, the numbers and
+// names of removed fields are reserved such that they are never reused. The types of
+// the fields are kept, such that a field whose type changes gets a new number.
+type ProtoLockMessage struct {
+	Fields        map[string]int    `yaml:"fields"`
+	Types         map[string]string `yaml:"types,omitempty"`
+	Reserved      []int             `yaml:"reserved,omitempty"`
+	ReservedNames []string          `yaml:"reservedNames,omitempty"`
+}
+
+// RenderProto writes a proto file per resource with a message per container of the
+// config and state trees of the resource. The field numbers are kept in a lock file
+// next to the proto files, which has to be kept with the generated files.
+func (g *Generator) RenderProto() error {
+	dir := filepath.Join(g.Config.OutputDir, "proto", g.Config.Prefix, g.Config.Version)
+	if err := g.output.MkdirAll(dir); err != nil {
+		return err
+	}
+	lockFileName := filepath.Join(dir, protoLockFileName)
+	lock, err := g.readProtoLock(lockFileName)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range g.Resources {
+		containers := append([]*container.Container{}, r.ContainerList...)
+		if st, ok := g.States[r]; ok {
+			containers = append(containers, st.ContainerList...)
+		}
+		messages := make([]*ProtoMessage, 0, len(containers))
+		for _, c := range containers {
+			messages = append(messages, g.protoMessage(lock, c))
+		}
+
+		f, err := g.output.Create(filepath.Join(dir, strcase.SnakeCase(g.Config.Prefix+"-"+r.GetAbsoluteName())+".proto"))
+		if err != nil {
+			return err
+		}
+		s := struct {
+			Package   string
+			GoPackage string
+			Messages  []*ProtoMessage
+		}{
+			Package:   g.Config.Prefix + "." + g.Config.Version,
+			GoPackage: g.Config.Module + "/proto/" + g.Config.Prefix + "/" + g.Config.Version + ";" + g.Config.Prefix + g.Config.Version,
+			Messages:  messages,
+		}
+		if err := g.executeTemplate(f, "proto"+".tmpl", s); err != nil {
+			g.log.Debug("Write proto error", "error", err)
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return g.writeProtoLock(lockFileName, lock)
+}
+
+// protoMessage returns the message of the container, the fields get the number of
+// the lock file and new fields get the next number that was never used in the message.
+// A field whose type changes is not wire compatible with its old number, the old number
+// is reserved and the field gets a new number. Its name cannot be reserved as long as
+// the field exists.
+func (g *Generator) protoMessage(lock *ProtoLock, c *container.Container) *ProtoMessage {
+	name := strcase.UpperCamelCase(c.GetFullName())
+	lm, ok := lock.Messages[name]
+	if !ok {
+		lm = &ProtoLockMessage{Fields: map[string]int{}}
+		lock.Messages[name] = lm
+	}
+	if lm.Fields == nil {
+		lm.Fields = map[string]int{}
+	}
+	if lm.Types == nil {
+		lm.Types = map[string]string{}
+	}
+
+	m := &ProtoMessage{Name: name}
+	present := make(map[string]bool)
+	for _, e := range g.containerEntries(c) {
+		f := &ProtoField{Name: strcase.SnakeCase(e.Name)}
+		switch {
+		case e.LeafList != nil:
+			f.Type = protoScalarType(e.Type)
+			f.Repeated = true
+		case e.Next != nil:
+			f.Type = strcase.UpperCamelCase(e.Next.GetFullName())
+			f.Repeated = e.Key != ""
+		default:
+			f.Type = protoScalarType(e.Type)
+			f.Optional = true
+		}
+		present[f.Name] = true
+		m.Fields = append(m.Fields, f)
+	}
+
+	// the fields which are removed from the message are reserved
+	for fieldName, number := range lm.Fields {
+		if !present[fieldName] {
+			delete(lm.Fields, fieldName)
+			delete(lm.Types, fieldName)
+			lm.Reserved = append(lm.Reserved, number)
+			lm.ReservedNames = append(lm.ReservedNames, fieldName)
+		}
+	}
+	// the numbers of the fields whose type changed are reserved
+	for _, f := range m.Fields {
+		number, ok := lm.Fields[f.Name]
+		if t, typed := lm.Types[f.Name]; ok && typed && t != f.declaredType() {
+			delete(lm.Fields, f.Name)
+			lm.Reserved = append(lm.Reserved, number)
+		}
+	}
+	used := make(map[int]bool)
+	next := 1
+	for _, number := range lm.Fields {
+		used[number] = true
+	}
+	for _, number := range lm.Reserved {
+		used[number] = true
+	}
+	for _, f := range m.Fields {
+		if number, ok := lm.Fields[f.Name]; ok {
+			f.Number = number
+			continue
+		}
+		for used[next] || (next >= protoReservedFirst && next <= protoReservedLast) {
+			next++
+		}
+		f.Number = next
+		used[next] = true
+		lm.Fields[f.Name] = next
+	}
+	// the types of a lock file without types are taken from the current fields
+	for _, f := range m.Fields {
+		lm.Types[f.Name] = f.declaredType()
+	}
+	// a name which is added again is no longer reserved, its old number stays reserved
+	reservedNames := make([]string, 0, len(lm.ReservedNames))
+	for _, fieldName := range lm.ReservedNames {
+		if !present[fieldName] {
+			reservedNames = append(reservedNames, fieldName)
+		}
+	}
+	lm.ReservedNames = uniqueStrings(reservedNames)
+	sort.Strings(lm.ReservedNames)
+	sort.Ints(lm.Reserved)
+
+	m.Reserved = lm.Reserved
+	m.ReservedNames = lm.ReservedNames
+	return m
+}
+
+// declaredType returns the type of the field with its label, a change of either one
+// changes the encoding of the field
+func (f *ProtoField) declaredType() string {
+	switch {
+	case f.Repeated:
+		return "repeated " + f.Type
+	case f.Optional:
+		return "optional " + f.Type
+	}
+	return f.Type
+}
+
+// protoScalarType returns the protobuf type of a leaf, enums are strings which hold
+// the yang value
+func protoScalarType(t string) string {
+	switch t {
+	case "int8", "int16", "int32":
+		return "int32"
+	case "int64":
+		return "int64"
+	case "uint8", "uint16", "uint32":
+		return "uint32"
+	case "uint64":
+		return "uint64"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	case "bool":
+		return "bool"
+	}
+	return "string"
+}
+
+// readProtoLock reads the lock file, an empty lock is returned when the file does not exist
+func (g *Generator) readProtoLock(fileName string) (*ProtoLock, error) {
+	lock := &ProtoLock{Messages: map[string]*ProtoLockMessage{}}
+	b, err := g.output.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, errors.Wrap(err, errProtoLockRead)
+	}
+	if err := yaml.Unmarshal(b, lock); err != nil {
+		return nil, errors.Wrap(err, errProtoLockUnMarshal)
+	}
+	if lock.Messages == nil {
+		lock.Messages = map[string]*ProtoLockMessage{}
+	}
+	return lock, nil
+}
+
+// writeProtoLock writes the lock file, the messages which are no longer generated are
+// kept such that their numbers are reused when they are generated again
+func (g *Generator) writeProtoLock(fileName string, lock *ProtoLock) error {
+	b, err := yaml.Marshal(lock)
+	if err != nil {
+		return errors.Wrap(err, errProtoLockMarshal)
+	}
+	if err := g.output.WriteFile(fileName, b); err != nil {
+		return errors.Wrap(err, errProtoLockWrite)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+	"github.com/netw-device-driver/ndd-ygen/pkg/templ"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+// protoTestLeaf is a leaf of the interface container the proto file is rendered from
+type protoTestLeaf struct {
+	name string
+	typ  string
+}
+
+// renderProtoTest renders the proto file of an interface resource with the leaves into
+// the output and returns the lines of the file
+func renderProtoTest(t *testing.T, output *MemoryOutput, leaves []protoTestLeaf) []string {
+	t.Helper()
+	tmpl, err := templ.ParseTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := container.NewContainer("interface", nil)
+	for _, l := range leaves {
+		e := container.NewEntry(l.name)
+		e.Type = l.typ
+		c.Entries = append(c.Entries, e)
+	}
+	r := resource.NewResource(resource.WithXPath("/srl_nokia-interfaces/interface"))
+	r.Container = c
+	r.ContainerList = []*container.Container{c}
+
+	g := &Generator{
+		Config: &GeneratorConfig{
+			OutputDir: "out",
+			Prefix:    "srl",
+			Version:   "v1",
+			Module:    "example.com/provider",
+		},
+		Resources: []*resource.Resource{r},
+		Template:  tmpl,
+		output:    output,
+		log:       logging.NewNopLogger(),
+	}
+	if err := g.RenderProto(); err != nil {
+		t.Fatal(err)
+	}
+	for fileName, b := range output.files {
+		if strings.HasSuffix(fileName, ".proto") {
+			lines := strings.Split(string(b), "\n")
+			for i := range lines {
+				lines[i] = strings.TrimSpace(lines[i])
+			}
+			return lines
+		}
+	}
+	t.Fatal("no proto file is rendered")
+	return nil
+}
+
+func TestRenderProtoFieldNumbers(t *testing.T) {
+	output := NewMemoryOutput()
+	first := []protoTestLeaf{
+		{name: "name", typ: "string"},
+		{name: "mtu", typ: "uint16"},
+		{name: "description", typ: "string"},
+		{name: "admin-state", typ: "string"},
+	}
+	// the description is removed, the mtu is retyped and the vlan-tagging is added
+	second := []protoTestLeaf{
+		{name: "name", typ: "string"},
+		{name: "mtu", typ: "string"},
+		{name: "admin-state", typ: "string"},
+		{name: "vlan-tagging", typ: "bool"},
+	}
+
+	cases := []struct {
+		leaves []protoTestLeaf
+		want   []string
+	}{
+		{
+			leaves: first,
+			want: []string{
+				"optional string name = 1;",
+				"optional uint32 mtu = 2;",
+				"optional string description = 3;",
+				"optional string admin_state = 4;",
+			},
+		},
+		{
+			leaves: second,
+			want: []string{
+				"reserved 2, 3;",
+				`reserved "description";`,
+				"optional string name = 1;",
+				"optional string mtu = 5;",
+				"optional string admin_state = 4;",
+				"optional bool vlan_tagging = 6;",
+			},
+		},
+		{
+			// the numbers are stable when nothing changes
+			leaves: second,
+			want: []string{
+				"reserved 2, 3;",
+				`reserved "description";`,
+				"optional string name = 1;",
+				"optional string mtu = 5;",
+				"optional string admin_state = 4;",
+				"optional bool vlan_tagging = 6;",
+			},
+		},
+	}
+	for i, tc := range cases {
+		lines := renderProtoTest(t, output, tc.leaves)
+		start := -1
+		for j, l := range lines {
+			if l == "message Interface {" {
+				start = j + 1
+				break
+			}
+		}
+		if start < 0 {
+			t.Fatalf("render %d: no message Interface in %v", i, lines)
+		}
+		got := lines[start : start+len(tc.want)]
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("render %d: message Interface\n%s\nwant\n%s", i, strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+		}
+		if lines[start+len(tc.want)] != "}" {
+			t.Errorf("render %d: message Interface has more fields than %v", i, tc.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// OutputFormatK8s renders the kubebuilder api types, controllers and webhooks
+	OutputFormatK8s = "k8s"
+	// OutputFormatCrds renders the CustomResourceDefinitions
+	OutputFormatCrds = "crds"
+
+	errUnknownOutputFormat = "unknown output format"
+)
+
+// Renderer renders the resource model that is built by the run of the generator
+// in an output format
+type Renderer interface {
+	Render(g *Generator) error
+}
+
+// RendererFunc is a function that implements the Renderer interface
+type RendererFunc func(g *Generator) error
+
+// Render implements Renderer
+func (f RendererFunc) Render(g *Generator) error {
+	return f(g)
+}
+
+// renderers holds the registered renderers by output format
+var renderers = map[string]Renderer{
+	OutputFormatK8s:        RendererFunc((*Generator).renderK8s),
+	OutputFormatCrds:       RendererFunc((*Generator).RenderCrds),
+	OutputFormatTerraform:  RendererFunc((*Generator).RenderTerraform),
+	OutputFormatProto:      RendererFunc((*Generator).RenderProto),
+	OutputFormatJSONSchema: RendererFunc((*Generator).RenderJSONSchemas),
+	OutputFormatDocs:       RendererFunc((*Generator).RenderDocs),
+}
+
+// RegisterRenderer registers the renderer of an output format, an existing renderer
+// of the output format is replaced
+func RegisterRenderer(format string, r Renderer) {
+	renderers[format] = r
+}
+
+// OutputFormats returns the output formats of the registered renderers
+func OutputFormats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Render renders the resources with the renderer of the output format of the generator,
+// the files are staged and only written to the output of the generator when the rendering
+// succeeds. The files of the previous generation which are no longer generated are removed.
+// A memory output keeps the staged files, such that they can be checked against the
+// output directory.
+func (g *Generator) Render() error {
+	r, ok := renderers[g.Config.OutputFormat]
+	if !ok {
+		return errors.Errorf("%s: %s", errUnknownOutputFormat, g.Config.OutputFormat)
+	}
+
+	// the output is already kept in memory when it is checked against the output directory
+	output := g.output
+	staged, check := output.(*MemoryOutput)
+	if !check {
+		staged = newMemoryOutput(output)
+		g.output = staged
+		defer func() { g.output = output }()
+	}
+	if err := r.Render(g); err != nil {
+		return err
+	}
+	if err := g.updateManifest(staged); err != nil {
+		return err
+	}
+	if check {
+		return nil
+	}
+	if _, ok := output.(diskOutput); ok {
+		return staged.Commit(g.Config.OutputDir)
+	}
+	return staged.CommitTo(output)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+)
+
+// the environment of the test binary when it renders the fixture in a separate process,
+// goyang keeps the merged submodules in global state and hence cannot process the same
+// yang modules twice in a process
+const (
+	envRenderFormat      = "NDD_YGEN_TEST_RENDER_FORMAT"
+	envRenderOutputDir   = "NDD_YGEN_TEST_RENDER_OUTPUT_DIR"
+	envRenderResourceMap = "NDD_YGEN_TEST_RENDER_RESOURCE_MAP"
+	envRenderFiles       = "NDD_YGEN_TEST_RENDER_FILES"
+)
+
+// fixtureResourceMap is the resource map the fixture is rendered from by default
+const fixtureResourceMap = "testdata/resourcemap.yaml"
+
+// renderFixture generates the resources of the resource map in the output format and
+// returns the rendered files
+func renderFixture(t *testing.T, format, outputDir, resourceMap string) map[string][]byte {
+	t.Helper()
+	output := NewMemoryOutput()
+	g, err := NewGenerator(
+		WithYangImportDirs([]string{"../../conf/yang/21_03_0/ietf"}),
+		WithYangModuleDirs([]string{"../../conf/yang/21_03_0/srl"}),
+		WithResourceMapInputFile(resourceMap),
+		WithOutputDir(outputDir),
+		WithPackageName("tfsrl"),
+		WithVersion("v1"),
+		WithAPIGroup("srl.ndd.henderiw.be"),
+		WithPrefix("srl"),
+		WithModule("example.com/provider"),
+		WithOutputFormat(format),
+		WithLogging(logging.NewNopLogger()),
+		WithLocalRender(true),
+		WithOutput(output),
+	)
+	if err != nil {
+		t.Fatalf("NewGenerator(): %v", err)
+	}
+	if err := g.Run(); err != nil {
+		t.Fatalf("Run(): %v", err)
+	}
+	if err := g.Render(); err != nil {
+		t.Fatalf("Render(): %v", err)
+	}
+	return output.files
+}
+
+// TestRenderProcess renders the fixture when the test binary is run by renderProcess
+// and writes the rendered files to the file in the environment
+func TestRenderProcess(t *testing.T) {
+	format := os.Getenv(envRenderFormat)
+	if format == "" {
+		t.Skip("the fixture is only rendered in a process started by renderProcess")
+	}
+	b, err := json.Marshal(renderFixture(t, format, os.Getenv(envRenderOutputDir), os.Getenv(envRenderResourceMap)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(os.Getenv(envRenderFiles), b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// renderProcess renders the resource map in a new process of the test binary and
+// returns the rendered files
+func renderProcess(t *testing.T, format, outputDir, resourceMap string) map[string][]byte {
+	t.Helper()
+	filesName := filepath.Join(t.TempDir(), "files.json")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRenderProcess$")
+	cmd.Env = append(os.Environ(),
+		envRenderFormat+"="+format,
+		envRenderOutputDir+"="+outputDir,
+		envRenderResourceMap+"="+resourceMap,
+		envRenderFiles+"="+filesName,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 2000 {
+			out = out[len(out)-2000:]
+		}
+		t.Fatalf("render %s: %v\n%s", format, err, out)
+	}
+	b, err := ioutil.ReadFile(filesName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	if err := json.Unmarshal(b, &files); err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestRenderReproducible(t *testing.T) {
+	for _, format := range OutputFormats() {
+		t.Run(format, func(t *testing.T) {
+			outputDir := t.TempDir()
+			first := renderProcess(t, format, outputDir, fixtureResourceMap)
+			second := renderProcess(t, format, outputDir, fixtureResourceMap)
+			if len(first) == 0 {
+				t.Fatalf("no files are rendered")
+			}
+			for fileName, b := range first {
+				other, ok := second[fileName]
+				fileName, _ = filepath.Rel(outputDir, fileName)
+				if !ok {
+					t.Errorf("%s is only rendered by the first run", fileName)
+					continue
+				}
+				if !bytes.Equal(b, other) {
+					t.Errorf("%s differs between the runs", fileName)
+				}
+			}
+			for fileName := range second {
+				if _, ok := first[fileName]; !ok {
+					fileName, _ = filepath.Rel(outputDir, fileName)
+					t.Errorf("%s is only rendered by the second run", fileName)
+				}
+			}
+		})
+	}
+}
@@ -29,12 +29,12 @@ import (
 )
 
 // FindBestMatch finds the string which matches the most
-func (g *Generator) FindBestMatch(path config.Path) (*resource.Resource, bool) {
+func (g *Generator) FindBestMatch(path *config.Path) (*resource.Resource, bool) {
 	minLength := 0
 	resMatch := &resource.Resource{}
 	found := false
 	for _, r := range g.Resources {
-		if strings.Contains(*g.parser.ConfigGnmiPathToXPath(&path, false), *r.GetAbsoluteXPath()) {
+		if strings.Contains(*g.parser.ConfigGnmiPathToXPath(path, false), *r.GetAbsoluteXPath()) {
 			// find the string which matches the most
 			// should be the last match normally since we added them
 			// to the list from root to lower hierarchy
@@ -50,7 +50,7 @@ func (g *Generator) FindBestMatch(path config.Path) (*resource.Resource, bool) {
 }
 
 // IsResourcesInit checks if the resource exists
-func (g *Generator) DoesResourceMatch(path config.Path) (*resource.Resource, bool) {
+func (g *Generator) DoesResourceMatch(path *config.Path) (*resource.Resource, bool) {
 	//fmt.Printf("Path: %s\n", *parser.GnmiPathToXPath(path))
 	if r, ok := g.FindBestMatch(path); ok {
 		//fmt.Printf("match path: %s \n", *r.GetAbsoluteXPath())
@@ -60,17 +60,27 @@ func (g *Generator) DoesResourceMatch(path config.Path) (*resource.Resource, boo
 	return nil, false
 }
 
-func (g *Generator) ResourceGenerator(resPath string, dynPath config.Path, e *yang.Entry) error {
+func (g *Generator) ResourceGenerator(resPath string, dynPath *config.Path, e *yang.Entry) error {
+	// choice and case statements are not part of the data tree, their children
+	// are processed as children of the parent of the choice
+	if isChoiceOrCase(e) {
+		return g.resourceGeneratorChildren(resPath, dynPath, e)
+	}
 	resPath += filepath.Join("/", e.Name)
 	//fmt.Printf("resource path1: %s \n", resPath)
-	dynPath.Elem = append(dynPath.Elem, g.parser.CreatePathElem(e))
+	dynPath = &config.Path{
+		Elem: append(dynPath.GetElem(), g.parser.CreatePathElem(e)),
+	}
 	//fmt.Printf("resource path2: %s \n", *parser.GnmiPathToXPath(&path, false))
 
 	if r, ok := g.DoesResourceMatch(dynPath); ok {
 		//fmt.Printf("match path: %s \n", *r.GetAbsoluteXPath())
 		switch {
 		case e.RPC != nil:
-		case e.ReadOnly():
+		case e.ReadOnly(): // this is a RO state element in yang
+			// the state elements are added to the state container tree of the resource
+			newLevel := strings.Count(resPath, "/") - strings.Count(*r.GetAbsoluteXPathWithoutKey(), "/")
+			g.StateGenerator(r, e, newLevel)
 		default: // this is a RW config element in yang
 			// find the containerPointer
 			// we look at the level delta from the root of the resource -> newLevel
@@ -89,7 +99,13 @@ func (g *Generator) ResourceGenerator(resPath string, dynPath config.Path, e *ya
 			if e.Kind.String() == "Leaf" {
 				fmt.Printf("Leaf Name: %s, ResPath: %s \n", e.Name, resPath)
 				// add entry to the container
-				cPtr.Entries = append(cPtr.Entries, g.parser.CreateContainerEntry(e, nil, nil))
+				ce := g.parser.CreateContainerEntry(e, nil, nil)
+				g.yangEntries[ce] = e
+				cPtr.Entries = append(cPtr.Entries, ce)
+				g.addChoiceEntry(cPtr, e, ce)
+				g.addLeafList(e, ce)
+				g.addEnum(cPtr, e, ce)
+				g.addStatements(r, cPtr, e, ce, resPath)
 				localPath, remotePath, local := g.parser.ProcessLeafRef(e, resPath, r.GetAbsoluteGnmiActualResourcePath())
 				if localPath != nil {
 					// validate if the leafrefs is a local leafref or an externaal leafref
@@ -115,10 +131,13 @@ func (g *Generator) ResourceGenerator(resPath string, dynPath config.Path, e *ya
 					r.ActualPath.Elem = append(r.ActualPath.Elem, g.parser.CreatePathElem(e))
 					// create a new container and apply to the root of the resource
 					r.Container = container.NewContainer(e.Name, nil)
+					g.yangContainers[r.Container] = e
 					// r.Container.Entries = append(r.Container.Entries, parser.CreateContainerEntry(e, nil, nil))
 					// append the container Ptr to the back of the list, to track the used container Pointers per level
 					// newLevel =0
 					r.SetRootContainerEntry(g.parser.CreateContainerEntry(e, nil, nil))
+					g.yangEntries[r.RootContainerEntry] = e
+					g.addStatements(r, nil, e, r.RootContainerEntry, resPath)
 					r.ContainerLevelKeys[newLevel] = make([]*container.Container, 0)
 					r.ContainerLevelKeys[newLevel] = append(r.ContainerLevelKeys[newLevel], r.Container)
 					r.ContainerList = append(r.ContainerList, r.Container)
@@ -128,12 +147,17 @@ func (g *Generator) ResourceGenerator(resPath string, dynPath config.Path, e *ya
 					r.ActualPath.Elem = append(r.ActualPath.Elem, g.parser.CreatePathElem(e))
 					// create a new container for the next iteration
 					c := container.NewContainer(e.Name, cPtr)
+					g.yangContainers[c] = e
 					if newLevel == 1 {
 						r.RootContainerEntry.Next = c
 					}
 					// allocate container entry to the original container Pointer and append to the container entry list
 					// the next pointer of the entry points to the new container
-					cPtr.Entries = append(cPtr.Entries, g.parser.CreateContainerEntry(e, c, cPtr))
+					ce := g.parser.CreateContainerEntry(e, c, cPtr)
+					g.yangEntries[ce] = e
+					cPtr.Entries = append(cPtr.Entries, ce)
+					g.addChoiceEntry(cPtr, e, ce)
+					g.addStatements(r, cPtr, e, ce, resPath)
 					// append the container Ptr to the back of the list, to track the used container Pointers per level
 					// initialize the level
 					r.ContainerLevelKeys[newLevel] = make([]*container.Container, 0)
@@ -143,7 +167,11 @@ func (g *Generator) ResourceGenerator(resPath string, dynPath config.Path, e *ya
 			}
 		}
 	}
-	// handles the recursive analysis of the yang tree
+	return g.resourceGeneratorChildren(resPath, dynPath, e)
+}
+
+// resourceGeneratorChildren handles the recursive analysis of the yang tree
+func (g *Generator) resourceGeneratorChildren(resPath string, dynPath *config.Path, e *yang.Entry) error {
 	var names []string
 	for k := range e.Dir {
 		names = append(names, k)
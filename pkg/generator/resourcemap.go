@@ -0,0 +1,388 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pkg/errors"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const (
+	errInvalidResourceMap = "invalid resource map input file"
+
+	// maxSuggestions is the maximum number of close matches that are suggested for an unknown node
+	maxSuggestions = 3
+)
+
+var (
+	// goTypeName matches the exported go identifiers the kinds, types and fields are renamed to
+	goTypeName = regexp.MustCompile(`^[A-Z][A-Za-z0-9_]*$`)
+	// kubernetesName matches the short names and categories of a kubernetes resource
+	kubernetesName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// printColumnTypes are the types of the printer columns of a kubernetes resource
+	printColumnTypes = []string{"string", "integer", "number", "boolean", "date"}
+)
+
+// validateResourceMapVersion validates the version of the resource map, the overrides of
+// the resources are only supported by version v2
+func validateResourceMapVersion(c *ResourceYamlInput) error {
+	switch c.Version {
+	case "", resourceMapV1:
+		if paths := overridePaths(c.Path, ""); len(paths) > 0 {
+			return errors.Errorf("%s: the overrides of %s require version %s", errInvalidResourceMap, strings.Join(paths, ", "), resourceMapV2)
+		}
+	case resourceMapV2:
+	default:
+		return errors.Errorf("%s: unknown version %s, supported versions are %s and %s", errInvalidResourceMap, c.Version, resourceMapV1, resourceMapV2)
+	}
+	return nil
+}
+
+// overridePaths returns the paths of the resource map that have overrides
+func overridePaths(pd map[string]PathDetails, parentPath string) []string {
+	paths := make([]string, 0)
+	for p, d := range pd {
+		if d.Kind != "" || len(d.ShortNames) > 0 || len(d.Categories) > 0 || d.Scope != "" ||
+			len(d.PrintColumns) > 0 || len(d.TypeNames) > 0 || len(d.FieldNames) > 0 {
+			paths = append(paths, parentPath+p)
+		}
+		paths = append(paths, overridePaths(d.Hierarchy, parentPath+p)...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ValidateResourceMap resolves the paths, hierarchies and excludes of the resource map
+// against the yang tree, all unknown nodes are reported with the close matches of
+// the nodes that exist at that position in the yang tree
+func (g *Generator) ValidateResourceMap(pd map[string]PathDetails) error {
+	if problems := g.resourceMapProblems(pd); len(problems) > 0 {
+		return errors.Errorf("%s: %s", errInvalidResourceMap, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// resourceMapProblems returns the problems of the paths of the resource map
+func (g *Generator) resourceMapProblems(pd map[string]PathDetails) []string {
+	problems := g.validateResourcePaths(pd, "", nil, g.yangModules())
+	// the resources of the same kind would overwrite each other
+	kinds := make(map[string]*resource.Resource)
+	for _, r := range g.Resources {
+		kind := g.kind(r)
+		if other, ok := kinds[kind]; ok {
+			problems = append(problems, "resource paths "+*other.GetAbsoluteXPath()+" and "+*r.GetAbsoluteXPath()+" have the same kind "+kind)
+			continue
+		}
+		kinds[kind] = r
+	}
+	return problems
+}
+
+// yangModules returns the yang module entries by module name
+func (g *Generator) yangModules() map[string]*yang.Entry {
+	modules := make(map[string]*yang.Entry)
+	for _, e := range g.Entries {
+		modules[e.Name] = e
+	}
+	return modules
+}
+
+// validateResourcePaths validates the paths relative to the parent yang entry, the
+// first element of a root path is the yang module
+func (g *Generator) validateResourcePaths(pd map[string]PathDetails, parentPath string, parent *yang.Entry, modules map[string]*yang.Entry) []string {
+	paths := make([]string, 0, len(pd))
+	for path := range pd {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	problems := make([]string, 0)
+	for _, p := range paths {
+		path := parentPath + p
+		elems := resourcePathElems(p)
+		if len(elems) == 0 {
+			problems = append(problems, "empty resource path")
+			continue
+		}
+		e := parent
+		if e == nil {
+			m, ok := modules[elems[0]]
+			if !ok {
+				problems = append(problems, unknownNode(path, "module", elems[0], mapKeys(modules)))
+				continue
+			}
+			e, elems = m, elems[1:]
+		}
+		e, problem := resolvePath(path, e, elems)
+		if problem != "" {
+			problems = append(problems, problem)
+			continue
+		}
+		switch {
+		case e.Parent == nil:
+			problems = append(problems, "resource path "+path+" is a yang module, the path must point to a container or list")
+			continue
+		case e.IsLeaf() || e.IsLeafList():
+			problems = append(problems, "resource path "+path+" is a leaf, the path must point to a container or list")
+			continue
+		case e.ReadOnly():
+			problems = append(problems, "resource path "+path+" is read-only, the path must point to a configurable container or list")
+			continue
+		}
+		for _, exclude := range pd[p].Excludes {
+			if _, problem := resolvePath(path+"/"+exclude, e, resourcePathElems(exclude)); problem != "" {
+				problems = append(problems, "exclude "+problem)
+			}
+		}
+		problems = append(problems, validateOverrides(path, e, pd[p])...)
+		if pd[p].Hierarchy != nil {
+			problems = append(problems, g.validateResourcePaths(pd[p].Hierarchy, path, e, modules)...)
+		}
+	}
+	return problems
+}
+
+// validateOverrides validates the overrides of the resource path against the yang entry
+// e of the resource
+func validateOverrides(path string, e *yang.Entry, d PathDetails) []string {
+	problems := make([]string, 0)
+	if d.Kind != "" && !goTypeName.MatchString(d.Kind) {
+		problems = append(problems, fmt.Sprintf("kind %s of resource path %s is not an exported go identifier", d.Kind, path))
+	}
+	for _, n := range d.ShortNames {
+		if !kubernetesName.MatchString(n) {
+			problems = append(problems, fmt.Sprintf("short name %s of resource path %s must consist of lower case alphanumeric characters or '-'", n, path))
+		}
+	}
+	for _, n := range d.Categories {
+		if !kubernetesName.MatchString(n) {
+			problems = append(problems, fmt.Sprintf("category %s of resource path %s must consist of lower case alphanumeric characters or '-'", n, path))
+		}
+	}
+	if d.Scope != "" && d.Scope != scopeCluster && d.Scope != scopeNamespaced {
+		problems = append(problems, fmt.Sprintf("unknown scope %s of resource path %s, the scope is %s or %s", d.Scope, path, scopeCluster, scopeNamespaced))
+	}
+	for _, c := range d.PrintColumns {
+		if c.Name == "" || c.JSONPath == "" {
+			problems = append(problems, fmt.Sprintf("print column of resource path %s must have a name and a jsonPath", path))
+		}
+		if !containsString(printColumnTypes, c.Type) {
+			problems = append(problems, fmt.Sprintf("unknown type %s of print column %s of resource path %s, the type is one of %s", c.Type, c.Name, path, strings.Join(printColumnTypes, ", ")))
+		}
+	}
+	for _, p := range sortedKeys(d.TypeNames) {
+		target, problem := resolveOverridePath(path, e, p)
+		switch {
+		case problem != "":
+			problems = append(problems, "type name "+problem)
+		case target.IsLeaf() || target.IsLeafList():
+			problems = append(problems, fmt.Sprintf("type name of %s in resource path %s must be a container or list", p, path))
+		case !goTypeName.MatchString(d.TypeNames[p]):
+			problems = append(problems, fmt.Sprintf("type name %s of %s in resource path %s is not an exported go identifier", d.TypeNames[p], p, path))
+		}
+	}
+	for _, p := range sortedKeys(d.FieldNames) {
+		target, problem := resolveOverridePath(path, e, p)
+		switch {
+		case problem != "":
+			problems = append(problems, "field name "+problem)
+		case target == e:
+			problems = append(problems, fmt.Sprintf("field name of %s in resource path %s cannot rename the root of the resource, the field is named by the kind", p, path))
+		case target.Parent != nil && target.Parent.ListAttr != nil && containsString(strings.Fields(target.Parent.Key), target.Name):
+			problems = append(problems, fmt.Sprintf("field name of %s in resource path %s cannot rename a key of a list", p, path))
+		case !goTypeName.MatchString(d.FieldNames[p]):
+			problems = append(problems, fmt.Sprintf("field name %s of %s in resource path %s is not an exported go identifier", d.FieldNames[p], p, path))
+		}
+	}
+	return problems
+}
+
+// resolveOverridePath returns the yang entry of the path of an override relative to the
+// yang entry e of the resource, the path starts with the name of the resource entry
+func resolveOverridePath(path string, e *yang.Entry, p string) (*yang.Entry, string) {
+	elems := resourcePathElems(p)
+	if len(elems) == 0 || elems[0] != e.Name {
+		return nil, fmt.Sprintf("path %s in resource path %s must start with /%s", p, path, e.Name)
+	}
+	return resolvePath(path+"/"+strings.Join(elems[1:], "/"), e, elems[1:])
+}
+
+// resolvePath returns the yang entry of the path elements relative to the yang entry
+// e, the problem describes the first element that does not exist
+func resolvePath(path string, e *yang.Entry, elems []string) (*yang.Entry, string) {
+	for _, name := range elems {
+		child := dataChild(e, name)
+		if child == nil {
+			return nil, unknownNode(path, "node", name, dataChildNames(e))
+		}
+		e = child
+	}
+	return e, ""
+}
+
+// resourcePathElems returns the names of the elements of a resource path, without
+// the keys and the module prefixes of the elements
+func resourcePathElems(path string) []string {
+	elems := make([]string, 0)
+	for _, elem := range strings.Split(path, "/") {
+		if i := strings.Index(elem, "["); i >= 0 {
+			elem = elem[:i]
+		}
+		if i := strings.Index(elem, ":"); i >= 0 {
+			elem = elem[i+1:]
+		}
+		elem = strings.TrimSpace(elem)
+		if elem != "" {
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// dataChild returns the data node child of the yang entry with the name, the children
+// of choice and case statements are children of the parent of the choice
+func dataChild(e *yang.Entry, name string) *yang.Entry {
+	if c, ok := e.Dir[name]; ok && !isChoiceOrCase(c) {
+		return c
+	}
+	for _, c := range e.Dir {
+		if isChoiceOrCase(c) {
+			if dc := dataChild(c, name); dc != nil {
+				return dc
+			}
+		}
+	}
+	return nil
+}
+
+// dataChildNames returns the names of the data node children of the yang entry
+func dataChildNames(e *yang.Entry) []string {
+	names := make([]string, 0, len(e.Dir))
+	for name, c := range e.Dir {
+		if isChoiceOrCase(c) {
+			names = append(names, dataChildNames(c)...)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// unknownNode describes an unknown element of a path with the close matches of the
+// element among the candidates
+func unknownNode(path, kind, name string, candidates []string) string {
+	problem := "unknown " + kind + " " + name + " in " + path
+	if suggestions := closeMatches(name, candidates); len(suggestions) > 0 {
+		problem += ", did you mean " + strings.Join(suggestions, " or ") + "?"
+	}
+	return problem
+}
+
+// closeMatches returns the candidates that are close to the name, ordered by their
+// edit distance to the name
+func closeMatches(name string, candidates []string) []string {
+	maxDistance := len(name) / 4
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	type match struct {
+		name     string
+		distance int
+	}
+	matches := make([]match, 0)
+	for _, c := range candidates {
+		d := editDistance(name, c)
+		if d <= maxDistance || strings.Contains(c, name) {
+			matches = append(matches, match{name: c, distance: d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+	suggestions := make([]string, 0, maxSuggestions)
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, matches[i].name)
+	}
+	return suggestions
+}
+
+// editDistance returns the levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, minInt(cur[j-1]+1, prev[j-1]+cost))
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// minInt returns the smallest of a and b
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// mapKeys returns the keys of the yang entry map
+func mapKeys(m map[string]*yang.Entry) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// sortedKeys returns the sorted keys of the map
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsString returns true when the list contains s
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/netw-device-driver/ndd-runtime/pkg/logging"
+)
+
+var (
+	validateFixtureOnce sync.Once
+	validateFixture     *Generator
+	validateFixtureErr  error
+)
+
+// newValidateFixture returns a generator that validates the fixture resource map, the
+// generator is shared by the tests since goyang cannot process the same yang modules
+// twice in a process
+func newValidateFixture(t *testing.T) *Generator {
+	t.Helper()
+	validateFixtureOnce.Do(func() {
+		validateFixture, validateFixtureErr = NewGenerator(
+			WithYangImportDirs([]string{"../../conf/yang/21_03_0/ietf"}),
+			WithYangModuleDirs([]string{"../../conf/yang/21_03_0/srl"}),
+			WithResourceMapInputFile("testdata/resourcemap.yaml"),
+			WithPrefix("srl"),
+			WithLogging(logging.NewNopLogger()),
+			WithValidateOnly(true),
+		)
+	})
+	if validateFixtureErr != nil {
+		t.Fatalf("NewGenerator(): %v", validateFixtureErr)
+	}
+	return validateFixture
+}
+
+func TestResourceMapProblems(t *testing.T) {
+	g := newValidateFixture(t)
+
+	cases := map[string]struct {
+		pd   map[string]PathDetails
+		want []string
+	}{
+		"Valid": {
+			pd: map[string]PathDetails{
+				"/srl_nokia-interfaces/interface": {
+					Excludes:  []string{"ethernet"},
+					Hierarchy: map[string]PathDetails{"/subinterface": {}},
+				},
+			},
+			want: []string{},
+		},
+		"MisspelledModule": {
+			pd: map[string]PathDetails{
+				"/srl_nokia-interface/interface": {},
+			},
+			want: []string{
+				"unknown module srl_nokia-interface in /srl_nokia-interface/interface, did you mean srl_nokia-interfaces or srl_nokia-interfaces-lag or srl_nokia-interfaces-nbr?",
+			},
+		},
+		"MisspelledPath": {
+			pd: map[string]PathDetails{
+				"/srl_nokia-interfaces/interfac": {},
+			},
+			want: []string{
+				"unknown node interfac in /srl_nokia-interfaces/interfac, did you mean interface?",
+			},
+		},
+		"MisspelledHierarchy": {
+			pd: map[string]PathDetails{
+				"/srl_nokia-interfaces/interface": {
+					Hierarchy: map[string]PathDetails{"/subinterfaces": {}},
+				},
+			},
+			want: []string{
+				"unknown node subinterfaces in /srl_nokia-interfaces/interface/subinterfaces, did you mean subinterface?",
+			},
+		},
+		"MisspelledExclude": {
+			pd: map[string]PathDetails{
+				"/srl_nokia-interfaces/interface": {
+					Excludes: []string{"ethernt"},
+				},
+			},
+			want: []string{
+				"exclude unknown node ethernt in /srl_nokia-interfaces/interface/ethernt, did you mean ethernet?",
+			},
+		},
+		"UnknownPath": {
+			pd: map[string]PathDetails{
+				"/srl_nokia-interfaces/interface/transceiver/wavelength-unknown": {},
+			},
+			want: []string{
+				"unknown node wavelength-unknown in /srl_nokia-interfaces/interface/transceiver/wavelength-unknown",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := g.resourceMapProblems(tc.pd)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("resourceMapProblems()\n%q\nwant\n%q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pkg/errors"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	validationReportFileName = "validation-report.yaml"
+
+	errReportMarshal = "cannot marshal validation report"
+	errReportWrite   = "cannot write validation report"
+)
+
+// Rule is a CEL validation rule of a generated type, which is translated from a
+// yang must or when statement
+type Rule struct {
+	Rule    string
+	Message string
+}
+
+// UntranslatedStatement is a yang must or when statement which cannot be
+// translated into a CEL validation rule
+type UntranslatedStatement struct {
+	Resource  string `yaml:"resource"`
+	Path      string `yaml:"path"`
+	Statement string `yaml:"statement"`
+	XPath     string `yaml:"xpath"`
+	Reason    string `yaml:"reason"`
+}
+
+// statement is a yang must or when statement that is translated when the
+// container trees of the resources are complete
+type statement struct {
+	r       *resource.Resource
+	scope   *container.Container // the container the rule is added to
+	node    []string             // the path from the scope to the context node of the xpath
+	guard   []string             // the path from the scope to the node the statement applies to, empty for the scope itself
+	kind    string               // must or when
+	xpath   string
+	message string
+	path    string
+}
+
+// addStatements records the must and when statements of the yang entry e, which is
+// added as container entry ce to container c. c is nil for the root of the resource.
+func (g *Generator) addStatements(r *resource.Resource, c *container.Container, e *yang.Entry, ce *container.Entry, resPath string) {
+	// a list is validated on its own type, other nodes on the type of their parent
+	s := &statement{r: r, scope: c, node: []string{e.Name}, guard: []string{e.Name}, path: resPath}
+	if c == nil || ce.Key != "" {
+		s = &statement{r: r, scope: ce.Next, path: resPath}
+		if c == nil {
+			s.scope = r.Container
+		}
+	}
+	for _, m := range entryMusts(e) {
+		ms := *s
+		ms.kind = "must"
+		ms.xpath = m.Name
+		ms.message = "must " + m.Name
+		if m.ErrorMessage != nil {
+			ms.message = m.ErrorMessage.Name
+		}
+		g.statements = append(g.statements, &ms)
+	}
+
+	// the when statement of a node is evaluated on the node, the one of an augment or
+	// uses on the parent of the node
+	ws := &statement{r: r, scope: c, node: []string{e.Name}, guard: []string{e.Name}, path: resPath, kind: "when"}
+	xpath, ok := e.GetWhenXPath()
+	if !ok {
+		ws.node = []string{}
+		xpath, ok = parentWhenXPath(e)
+	}
+	if !ok {
+		return
+	}
+	ws.xpath = xpath
+	ws.message = e.Name + " is only allowed when " + xpath
+	g.statements = append(g.statements, ws)
+}
+
+// entryMusts returns the must statements of the yang entry
+func entryMusts(e *yang.Entry) []*yang.Must {
+	switch n := e.Node.(type) {
+	case *yang.Leaf:
+		return n.Must
+	case *yang.LeafList:
+		return n.Must
+	case *yang.Container:
+		return n.Must
+	case *yang.List:
+		return n.Must
+	}
+	return nil
+}
+
+// parentWhenXPath returns the when statement of the augment or uses that added the
+// yang entry to its parent
+func parentWhenXPath(e *yang.Entry) (string, bool) {
+	if e.Node == nil {
+		return "", false
+	}
+	if a, ok := e.Node.ParentNode().(*yang.Augment); ok && a.When != nil {
+		return a.When.Name, true
+	}
+	if p := dataParent(e); p != nil {
+		for _, u := range p.Uses {
+			if u.Uses.When == nil || u.Grouping == nil {
+				continue
+			}
+			if _, ok := u.Grouping.Dir[e.Name]; ok {
+				return u.Uses.When.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// TranslateStatements translates the recorded must and when statements into CEL
+// validation rules, the statements which cannot be translated are reported
+func (g *Generator) TranslateStatements() {
+	for _, s := range g.statements {
+		scope, rule, err := g.translateStatement(s)
+		if err != nil {
+			g.Untranslated = append(g.Untranslated, &UntranslatedStatement{
+				Resource:  g.kind(s.r),
+				Path:      s.path,
+				Statement: s.kind,
+				XPath:     s.xpath,
+				Reason:    err.Error(),
+			})
+			continue
+		}
+		g.Rules[scope] = append(g.Rules[scope], &Rule{
+			Rule:    rule,
+			Message: s.message,
+		})
+	}
+}
+
+// translateStatement returns the CEL rule of the statement, when the xpath references
+// nodes outside of the scope, the scope is moved up to the parent container as long
+// as the scope is not an entry of a list
+func (g *Generator) translateStatement(s *statement) (*container.Container, string, error) {
+	scope, node, guard := s.scope, s.node, s.guard
+	for {
+		if scope == nil {
+			return nil, "", errors.New(errXPathLeavesScope)
+		}
+		rule, err := g.translateXPath(scope, node, s.xpath)
+		if err == nil {
+			return scope, guardRule(guard, rule), nil
+		}
+		if err.Error() != errXPathLeavesScope || scope.Prev == nil {
+			return nil, "", err
+		}
+		var pe *container.Entry
+		for _, e := range scope.Prev.Entries {
+			if e.Next == scope {
+				pe = e
+			}
+		}
+		if pe == nil || pe.Key != "" {
+			return nil, "", err
+		}
+		scope = scope.Prev
+		node = append([]string{pe.Name}, node...)
+		guard = append([]string{pe.Name}, guard...)
+	}
+}
+
+// guardRule returns the rule which only applies when the node of the guard exists
+func guardRule(guard []string, rule string) string {
+	if len(guard) == 0 {
+		return rule
+	}
+	field := "self"
+	exists := make([]string, 0, len(guard))
+	for _, name := range guard {
+		field += "." + celFieldName(name)
+		exists = append(exists, "has("+field+")")
+	}
+	if len(exists) == 1 {
+		return "!" + exists[0] + " || " + rule
+	}
+	return "!(" + strings.Join(exists, " && ") + ") || " + rule
+}
+
+// RenderValidationReport writes the must and when statements which are not translated
+// into validation rules, the report is not written when all statements are translated
+func (g *Generator) RenderValidationReport() error {
+	fileName := filepath.Join(g.Config.OutputDir, validationReportFileName)
+	if len(g.Untranslated) == 0 {
+		if err := g.output.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, errReportWrite)
+		}
+		return nil
+	}
+	b, err := yaml.Marshal(g.Untranslated)
+	if err != nil {
+		return errors.Wrap(err, errReportMarshal)
+	}
+	if err := g.output.WriteFile(fileName, b); err != nil {
+		return errors.Wrap(err, errReportWrite)
+	}
+	return nil
+}
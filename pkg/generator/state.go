@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+// State holds the read-only (config false) container tree of a resource.
+// The tree is built in parallel to the config container tree of the resource
+// and is rendered in the Observation of the resource.
+type State struct {
+	root          *yang.Entry                          // yang entry of the resource root
+	Container     *container.Container                 // root container of the state tree
+	ContainerList []*container.Container               // List of all containers within the state tree
+	containers    map[*yang.Entry]*container.Container // maps the yang entries to their state container
+}
+
+// NewState initializes the state tree of a resource with root as the yang entry of the resource root
+func NewState(root *yang.Entry) *State {
+	return &State{
+		root:          root,
+		ContainerList: make([]*container.Container, 0),
+		containers:    make(map[*yang.Entry]*container.Container),
+	}
+}
+
+// StateGenerator adds a read-only yang entry to the state tree of the resource.
+// level is the level delta of the yang entry from the root of the resource.
+func (g *Generator) StateGenerator(r *resource.Resource, e *yang.Entry, level int) {
+	s, ok := g.States[r]
+	if !ok {
+		// the resource root is the ancestor of the entry at the level delta
+		root := e
+		for i := 0; i < level; i++ {
+			root = dataParent(root)
+		}
+		s = NewState(root)
+		g.States[r] = s
+	}
+	// the parent container is created when the first read-only child is found
+	cPtr := g.stateContainer(s, dataParent(e))
+
+	if e.Kind.String() == "Leaf" {
+		ce := g.parser.CreateContainerEntry(e, nil, nil)
+		cPtr.Entries = append(cPtr.Entries, ce)
+		g.yangEntries[ce] = e
+		g.addLeafList(e, ce)
+		g.addEnum(cPtr, e, ce)
+		return
+	}
+	g.stateContainer(s, e)
+}
+
+// stateContainer returns the state container of the yang entry, when the container
+// does not exist it is created together with the missing containers of its parents.
+func (g *Generator) stateContainer(s *State, e *yang.Entry) *container.Container {
+	if c, ok := s.containers[e]; ok {
+		return c
+	}
+	var c *container.Container
+	if e == s.root {
+		// the root is named differently to avoid a name clash with the config container
+		c = container.NewContainer(e.Name+"-state", nil)
+		s.Container = c
+	} else {
+		cPtr := g.stateContainer(s, dataParent(e))
+		c = container.NewContainer(e.Name, cPtr)
+		ce := g.parser.CreateContainerEntry(e, c, cPtr)
+		cPtr.Entries = append(cPtr.Entries, ce)
+		g.yangEntries[ce] = e
+	}
+	g.yangContainers[c] = e
+	// config lists carry their keys in the state tree, such that the observed
+	// list entries can be related to the configured ones
+	if !e.ReadOnly() {
+		for _, k := range strings.Fields(e.Key) {
+			if ke, ok := e.Dir[k]; ok {
+				ce := g.parser.CreateContainerEntry(ke, nil, nil)
+				c.Entries = append(c.Entries, ce)
+				g.yangEntries[ce] = ke
+				g.addEnum(c, ke, ce)
+			}
+		}
+	}
+	s.containers[e] = c
+	s.ContainerList = append(s.ContainerList, c)
+	return c
+}
+
+// stateRoot returns the type name of the state root container of the resource,
+// the name is empty when the resource has no read-only elements
+func (g *Generator) stateRoot(r *resource.Resource) string {
+	if s, ok := g.States[r]; ok {
+		return strcase.UpperCamelCase(s.Container.GetFullName())
+	}
+	return ""
+}
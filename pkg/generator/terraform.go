@@ -0,0 +1,304 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const (
+	// OutputFormatTerraform renders the terraform provider schemas
+	OutputFormatTerraform = "terraform"
+
+	errTerraformWrite = "cannot write terraform resource"
+)
+
+// TerraformSchema is the schema of an attribute or a nested block of a terraform resource
+type TerraformSchema struct {
+	Name        string
+	Type        string // the schema.ValueType, TypeString, TypeInt, TypeBool, TypeList or TypeSet
+	Description string
+	Required    bool
+	ForceNew    bool
+	Default     string // the go expression of the default value
+	MinItems    uint64
+	MaxItems    uint64
+	Validators  []string           // the go expressions of the validation functions
+	Elem        *TerraformSchema   // the element schema of a leaf-list
+	Block       []*TerraformSchema // the schema of a nested block
+}
+
+// RenderTerraform writes the terraform schema.Resource of every resource and the
+// map of the resources by terraform type name
+func (g *Generator) RenderTerraform() error {
+	dir := filepath.Join(g.Config.OutputDir, "terraform", g.Config.PackageName)
+	if err := g.output.MkdirAll(dir); err != nil {
+		return err
+	}
+
+	type terraformResource struct {
+		TypeName string
+		Kind     string
+	}
+	resources := make([]*terraformResource, 0, len(g.Resources))
+	for _, r := range g.Resources {
+		tr := &terraformResource{
+			TypeName: strcase.SnakeCase(g.Config.Prefix + "-" + r.GetAbsoluteName()),
+			Kind:     g.kind(r),
+		}
+		schema := g.terraformResourceSchema(r)
+		validators := terraformValidators(schema)
+		s := struct {
+			Package        string
+			Kind           string
+			TypeName       string
+			Schema         []*TerraformSchema
+			UsesValidation bool
+			UsesRegexp     bool
+		}{
+			Package:        g.Config.PackageName,
+			Kind:           tr.Kind,
+			TypeName:       tr.TypeName,
+			Schema:         schema,
+			UsesValidation: len(validators) > 0,
+			UsesRegexp:     strings.Contains(strings.Join(validators, "\n"), "regexp."),
+		}
+		if err := g.writeTerraformFile(filepath.Join(dir, "resource_"+tr.TypeName+".go"), "terraformResource", r, s); err != nil {
+			g.log.Debug("Write terraform resource error", "error", err)
+			return err
+		}
+		resources = append(resources, tr)
+	}
+
+	s := struct {
+		Package   string
+		Resources []*terraformResource
+	}{
+		Package:   g.Config.PackageName,
+		Resources: resources,
+	}
+	if err := g.writeTerraformFile(filepath.Join(dir, "provider.go"), "terraformProvider", nil, s); err != nil {
+		g.log.Debug("Write terraform provider error", "error", err)
+		return err
+	}
+	return nil
+}
+
+// writeTerraformFile executes the template and writes the formatted go source, the
+// nested blocks are rendered recursively and are indented by the formatting
+func (g *Generator) writeTerraformFile(fileName, tmplName string, r *resource.Resource, data interface{}) error {
+	f := g.createGoFile(fileName, r)
+	if err := g.executeTemplate(f, tmplName+".tmpl", data); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, errTerraformWrite)
+	}
+	return nil
+}
+
+// terraformResourceSchema returns the schema of the resource, which holds the network
+// node, the keys of the hierarchical resources and the root container as a nested block
+func (g *Generator) terraformResourceSchema(r *resource.Resource) []*TerraformSchema {
+	schema := []*TerraformSchema{
+		{
+			Name:        "network_node",
+			Type:        "TypeString",
+			Description: "the network node the resource is configured on",
+			Default:     strconv.Quote("default"),
+			ForceNew:    true,
+		},
+	}
+	for _, k := range g.hierarchyKeys(r) {
+		s := g.terraformLeafSchema(&container.Entry{Type: k.Type})
+		s.Name = strcase.SnakeCase(k.Name + "-" + k.Key)
+		s.Required = true
+		s.ForceNew = true
+		schema = append(schema, s)
+	}
+	block := g.terraformContainerSchema(r.Container)
+	// the keys of the root container identify the resource on the network node
+	if r.RootContainerEntry != nil {
+		terraformKeys(block, r.RootContainerEntry.Key, true)
+	}
+	schema = append(schema, &TerraformSchema{
+		Name:     strcase.SnakeCase(r.ResourceLastElement()),
+		Type:     "TypeList",
+		Required: true,
+		MinItems: 1,
+		MaxItems: 1,
+		Block:    block,
+	})
+	return schema
+}
+
+// terraformKeys makes the key attributes of a block required
+func terraformKeys(block []*TerraformSchema, key string, forceNew bool) {
+	for _, k := range strings.Fields(key) {
+		for _, s := range block {
+			if s.Name == strcase.SnakeCase(k) {
+				s.Required = true
+				s.ForceNew = forceNew
+				s.Default = ""
+			}
+		}
+	}
+}
+
+// terraformContainerSchema returns the schema of the entries of a container, nested
+// containers are blocks with a single element and lists are blocks with an element per
+// list entry. The entries of lists which are ordered by the user are kept in order.
+func (g *Generator) terraformContainerSchema(c *container.Container) []*TerraformSchema {
+	schema := make([]*TerraformSchema, 0, len(c.Entries))
+	for _, e := range g.containerEntries(c) {
+		var s *TerraformSchema
+		switch {
+		case e.LeafList != nil:
+			// leaf-list in the container
+			s = &TerraformSchema{
+				Type:     "TypeList",
+				Required: e.Mandatory || e.LeafList.MinElements > 0,
+				MinItems: e.LeafList.MinElements,
+				MaxItems: e.LeafList.MaxElements,
+				Elem:     g.terraformLeafSchema(e.Entry),
+			}
+			s.Elem.Default = ""
+		case e.Next != nil && e.Key != "":
+			// list in the container
+			s = &TerraformSchema{
+				Type:  "TypeSet",
+				Block: g.terraformContainerSchema(e.Next),
+			}
+			if ye, ok := g.yangEntries[e.Entry]; ok && ye.ListAttr != nil {
+				if ye.ListAttr.OrderedBy != nil && ye.ListAttr.OrderedBy.Name == "user" {
+					s.Type = "TypeList"
+				}
+			}
+			// the keys of a list are required in every list entry
+			terraformKeys(s.Block, e.Key, false)
+		case e.Next != nil:
+			// container in the container
+			s = &TerraformSchema{
+				Type:     "TypeList",
+				MaxItems: 1,
+				Block:    g.terraformContainerSchema(e.Next),
+			}
+		default:
+			// regular leaf in the container
+			s = g.terraformLeafSchema(e.Entry)
+			s.Required = e.Mandatory
+			if s.Required {
+				s.Default = ""
+			}
+		}
+		s.Name = strcase.SnakeCase(e.Name)
+		schema = append(schema, s)
+	}
+	return schema
+}
+
+// terraformValidators returns the validators of the schema and its nested schemas
+func terraformValidators(schema []*TerraformSchema) []string {
+	validators := make([]string, 0)
+	for _, s := range schema {
+		validators = append(validators, s.Validators...)
+		if s.Elem != nil {
+			validators = append(validators, s.Elem.Validators...)
+		}
+		validators = append(validators, terraformValidators(s.Block)...)
+	}
+	return validators
+}
+
+// terraformLeafSchema returns the schema of a leaf, using the type, range, length,
+// pattern, enum and default information of the container entry
+func (g *Generator) terraformLeafSchema(e *container.Entry) *TerraformSchema {
+	s := &TerraformSchema{}
+	switch e.Type {
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		s.Type = "TypeInt"
+	case "bool":
+		s.Type = "TypeBool"
+	default:
+		s.Type = "TypeString"
+	}
+
+	if s.Type == "TypeInt" {
+		min, max := g.rangeBounds(e)
+		switch {
+		case min != nil && max != nil:
+			s.Validators = append(s.Validators, "validation.IntBetween("+strconv.FormatInt(*min, 10)+", "+strconv.FormatInt(*max, 10)+")")
+		case min != nil:
+			s.Validators = append(s.Validators, "validation.IntAtLeast("+strconv.FormatInt(*min, 10)+")")
+		case max != nil:
+			s.Validators = append(s.Validators, "validation.IntAtMost("+strconv.FormatInt(*max, 10)+")")
+		}
+	}
+	if s.Type == "TypeString" {
+		// a length without a maximum is not validated, the minimum defaults to 0
+		if min, max := g.lengthBounds(e); max != nil {
+			lmin := int64(0)
+			if min != nil {
+				lmin = *min
+			}
+			s.Validators = append(s.Validators, "validation.StringLenBetween("+strconv.FormatInt(lmin, 10)+", "+strconv.FormatInt(*max, 10)+")")
+		}
+	}
+	// patterns which go does not support are not validated, as the provider would
+	// panic when the resources are initialized
+	if p := enforceablePattern(e); p != "" && s.Type == "TypeString" {
+		if _, err := regexp.Compile(p); err == nil {
+			s.Validators = append(s.Validators, "validation.StringMatch(regexp.MustCompile("+strconv.Quote(p)+"), \"\")")
+		}
+	}
+	if len(e.Enum) > 0 && s.Type == "TypeString" {
+		values := make([]string, 0, len(e.Enum))
+		for _, v := range e.Enum {
+			values = append(values, strconv.Quote(v))
+		}
+		s.Validators = append(s.Validators, "validation.StringInSlice([]string{"+strings.Join(values, ", ")+"}, false)")
+	}
+	if e.Default != "" {
+		s.Default = terraformDefault(s.Type, e.Default)
+	}
+	return s
+}
+
+// terraformDefault returns the go expression of the default value of a leaf
+func terraformDefault(t, d string) string {
+	switch t {
+	case "TypeInt":
+		if i, err := strconv.ParseInt(d, 10, 64); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+		return ""
+	case "TypeBool":
+		if b, err := strconv.ParseBool(d); err == nil {
+			return strconv.FormatBool(b)
+		}
+		return ""
+	}
+	return strconv.Quote(d)
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	config "github.com/netw-device-driver/ndd-grpc/config/configpb"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+// Severity of a validation finding
+type Severity string
+
+const (
+	// SeverityError is a finding that breaks the generated provider
+	SeverityError Severity = "error"
+	// SeverityWarning is a finding that is reported but does not break the generated provider
+	SeverityWarning Severity = "warning"
+)
+
+// the checks that are performed by the validation
+const (
+	CheckYang        = "yang"
+	CheckResourceMap = "resource-map"
+	CheckOverlap     = "overlap"
+	CheckCoverage    = "coverage"
+	CheckLeafRef     = "leafref"
+)
+
+// Finding is a problem that is found by the validation
+type Finding struct {
+	Severity Severity
+	Check    string
+	Message  string
+}
+
+// Validate checks the yang modules and the resource map: the processing errors of the
+// yang modules, the paths of the resource map which do not exist in yang, the resources
+// that overlap, the config subtrees that are not covered by a resource and the leafrefs
+// which cannot be resolved. The resources are only generated to check the leafrefs when
+// the resource map resolves against yang.
+func (g *Generator) Validate() ([]*Finding, error) {
+	findings := make([]*Finding, 0)
+	for _, d := range g.diagnostics {
+		findings = append(findings, &Finding{Severity: SeverityError, Check: CheckYang, Message: d.String()})
+	}
+	problems := g.resourceMapProblems(g.resourceMap)
+	for _, p := range problems {
+		findings = append(findings, &Finding{Severity: SeverityError, Check: CheckResourceMap, Message: p})
+	}
+	if len(problems) > 0 {
+		return findings, nil
+	}
+
+	findings = append(findings, g.overlappingResources()...)
+	findings = append(findings, g.uncoveredSubtrees()...)
+
+	if err := g.Run(); err != nil {
+		return nil, err
+	}
+	findings = append(findings, g.unresolvedLeafRefs()...)
+	return findings, nil
+}
+
+// overlappingResources returns the resources which are defined more than once and the
+// resources which are nested in another resource without being part of its hierarchy,
+// the yang nodes of a nested resource are taken away from the resource it is nested in
+func (g *Generator) overlappingResources() []*Finding {
+	findings := make([]*Finding, 0)
+	for i, r := range g.Resources {
+		for j, o := range g.Resources {
+			if i == j {
+				continue
+			}
+			path, otherPath := pathElemNames(r.GetAbsoluteGnmiPath()), pathElemNames(o.GetAbsoluteGnmiPath())
+			switch {
+			case equalElems(path, otherPath):
+				if i < j {
+					findings = append(findings, &Finding{
+						Severity: SeverityError,
+						Check:    CheckOverlap,
+						Message:  "resource " + *r.GetAbsoluteXPath() + " is defined more than once",
+					})
+				}
+			case hasPrefixElems(path, otherPath) && !dependsOn(r, o):
+				findings = append(findings, &Finding{
+					Severity: SeverityError,
+					Check:    CheckOverlap,
+					Message:  "resource " + *r.GetAbsoluteXPath() + " is nested in resource " + *o.GetAbsoluteXPath() + " but is not part of its hierarchy",
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// uncoveredSubtrees returns the config subtrees of the yang modules of the resource map
+// which are not covered by a resource and which are not excluded
+func (g *Generator) uncoveredSubtrees() []*Finding {
+	resources := make([][]string, 0, len(g.Resources))
+	excludes := make([][]string, 0)
+	modules := make(map[string]bool)
+	for _, r := range g.Resources {
+		path := pathElemNames(r.GetAbsoluteGnmiPath())
+		resources = append(resources, path)
+		modules[path[0]] = true
+		// the excludes are relative to the parent resource
+		parent := make([]string, 0)
+		if r.DependsOn != nil {
+			parent = pathElemNames(r.DependsOn.GetAbsoluteGnmiPath())
+		}
+		for _, e := range r.Excludes {
+			excludes = append(excludes, append(append([]string{}, parent...), pathElemNames(e)...))
+		}
+	}
+
+	findings := make([]*Finding, 0)
+	var walk func(e *yang.Entry, path []string)
+	walk = func(e *yang.Entry, path []string) {
+		for _, name := range sortedDataChildNames(e) {
+			c := dataChild(e, name)
+			if c.ReadOnly() || c.RPC != nil || c.Kind == yang.NotificationEntry {
+				continue
+			}
+			childPath := append(append([]string{}, path...), name)
+			switch {
+			case containsElems(resources, childPath) || containsElems(excludes, childPath):
+			case !hasNestedElems(resources, childPath):
+				findings = append(findings, &Finding{
+					Severity: SeverityWarning,
+					Check:    CheckCoverage,
+					Message:  "config subtree /" + strings.Join(childPath, "/") + " is not covered by a resource",
+				})
+			default:
+				walk(c, childPath)
+			}
+		}
+	}
+	for _, m := range g.Entries {
+		if modules[m.Name] {
+			walk(m, []string{m.Name})
+		}
+	}
+	return findings
+}
+
+// unresolvedLeafRefs returns the leafrefs of which the referenced node does not exist in
+// yang and the external leafrefs of which the referenced node is not part of a resource
+func (g *Generator) unresolvedLeafRefs() []*Finding {
+	findings := make([]*Finding, 0)
+	for _, r := range g.Resources {
+		// the referenced path of a local leafref is relative to the parent of the resource
+		parent := r.GetAbsoluteGnmiPath().GetElem()
+		parent = parent[1 : len(parent)-1]
+		for _, lr := range r.GetLocalLeafRef() {
+			if !g.leafRefTargetExists(&config.Path{Elem: append(append([]*config.PathElem{}, parent...), lr.RemotePath.GetElem()...)}) {
+				findings = append(findings, g.unresolvedLeafRef(r, lr.LocalPath, lr.RemotePath, "does not exist in yang"))
+			}
+		}
+		for _, lr := range r.GetExternalLeafRef() {
+			switch {
+			case !g.leafRefTargetExists(lr.RemotePath):
+				findings = append(findings, g.unresolvedLeafRef(r, lr.LocalPath, lr.RemotePath, "does not exist in yang"))
+			case !g.leafRefTargetCovered(lr.RemotePath):
+				findings = append(findings, g.unresolvedLeafRef(r, lr.LocalPath, lr.RemotePath, "is not part of a resource in the resource map"))
+			}
+		}
+	}
+	return findings
+}
+
+func (g *Generator) unresolvedLeafRef(r *resource.Resource, localPath, remotePath *config.Path, reason string) *Finding {
+	return &Finding{
+		Severity: SeverityError,
+		Check:    CheckLeafRef,
+		Message: "leafref " + *g.parser.ConfigGnmiPathToXPath(localPath, false) + " of resource " + *r.GetAbsoluteXPath() +
+			" references " + *g.parser.ConfigGnmiPathToXPath(remotePath, false) + " which " + reason,
+	}
+}
+
+// leafRefTargetExists checks if the referenced path of a leafref exists in one of the
+// yang modules, the referenced path does not contain the module name
+func (g *Generator) leafRefTargetExists(p *config.Path) bool {
+	elems := pathElemNames(p)
+	for _, m := range g.Entries {
+		if _, problem := resolvePath("", m, elems); problem == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// leafRefTargetCovered checks if the referenced path of a leafref is part of a resource
+func (g *Generator) leafRefTargetCovered(p *config.Path) bool {
+	elems := pathElemNames(p)
+	for _, r := range g.Resources {
+		// the path of the resource starts with the module name
+		if path := pathElemNames(r.GetAbsoluteGnmiPath()); hasPrefixElems(elems, path[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathElemNames returns the names of the elements of the path
+func pathElemNames(p *config.Path) []string {
+	names := make([]string, 0, len(p.GetElem()))
+	for _, pe := range p.GetElem() {
+		names = append(names, pe.GetName())
+	}
+	return names
+}
+
+// sortedDataChildNames returns the names of the data node children of the yang entry in order
+func sortedDataChildNames(e *yang.Entry) []string {
+	names := dataChildNames(e)
+	sort.Strings(names)
+	return names
+}
+
+// dependsOn checks if the resource is part of the hierarchy of the other resource
+func dependsOn(r, o *resource.Resource) bool {
+	for d := r.DependsOn; d != nil; d = d.DependsOn {
+		if d == o {
+			return true
+		}
+	}
+	return false
+}
+
+// equalElems checks if both paths have the same elements
+func equalElems(a, b []string) bool {
+	return len(a) == len(b) && hasPrefixElems(a, b)
+}
+
+// hasPrefixElems checks if the path starts with the elements of the prefix
+func hasPrefixElems(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// containsElems checks if the path is one of the paths
+func containsElems(paths [][]string, path []string) bool {
+	for _, p := range paths {
+		if equalElems(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNestedElems checks if one of the paths is nested in the path
+func hasNestedElems(paths [][]string, path []string) bool {
+	for _, p := range paths {
+		if hasPrefixElems(p, path) {
+			return true
+		}
+	}
+	return false
+}
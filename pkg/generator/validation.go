@@ -0,0 +1,110 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"io"
+	"path/filepath"
+
+	"github.com/yndd/ndd-yang/pkg/container"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+const validationFileName = "zz_generated.validation.go"
+
+// RenderValidation writes the validating webhooks of the resources, which check
+// that only one case of every yang choice in the resource is set
+func (g *Generator) RenderValidation() error {
+	f := g.createGoFile(filepath.Join(g.Config.OutputDir, "api", g.Config.Version, validationFileName), nil)
+	defer f.Close()
+
+	if err := g.WriteValidationHeader(f); err != nil {
+		g.log.Debug("Write validation header error", "error", err)
+		return err
+	}
+	for _, r := range g.Resources {
+		f.forResource(r)
+		for _, c := range r.ContainerList {
+			if !g.hasChoices(c) {
+				continue
+			}
+			if err := g.WriteValidationContainer(f, c); err != nil {
+				g.log.Debug("Write validation container error", "error", err)
+				return err
+			}
+		}
+		if err := g.WriteValidationResource(f, r); err != nil {
+			g.log.Debug("Write validation resource error", "error", err)
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// WriteValidationHeader
+func (g *Generator) WriteValidationHeader(w io.Writer) error {
+	s := struct {
+		Version string
+	}{
+		Version: g.Config.Version,
+	}
+	return g.executeTemplate(w, "validationHeader"+".tmpl", s)
+}
+
+// WriteValidationContainer writes the validation of the choices of the container
+// and of the child containers which have choices
+func (g *Generator) WriteValidationContainer(w io.Writer, c *container.Container) error {
+	children := make([]*container.Entry, 0)
+	for _, e := range c.Entries {
+		if e.Next != nil && g.hasChoices(e.Next) {
+			children = append(children, e)
+		}
+	}
+	s := struct {
+		Name       string
+		TypeName   string
+		FieldNames map[*container.Entry]string
+		Choices    []*Choice
+		Children   []*container.Entry
+	}{
+		Name:       c.GetFullName(),
+		TypeName:   g.goTypeName(c),
+		FieldNames: g.goFieldNames(c),
+		Choices:    g.Choices[c],
+		Children:   children,
+	}
+	return g.executeTemplate(w, "validationContainer"+".tmpl", s)
+}
+
+// WriteValidationResource writes the validating webhook of the resource
+func (g *Generator) WriteValidationResource(w io.Writer, r *resource.Resource) error {
+	kind := g.kind(r)
+	s := struct {
+		ApiGroup               string
+		Version                string
+		ResourceNameWithPrefix string
+		Plural                 string
+		HasChoices             bool
+	}{
+		ApiGroup:               g.Config.ApiGroup,
+		Version:                g.Config.Version,
+		ResourceNameWithPrefix: kind,
+		Plural:                 kindPlural(kind),
+		HasChoices:             r.Container != nil && g.hasChoices(r.Container),
+	}
+	return g.executeTemplate(w, "validationResource"+".tmpl", s)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/resource"
+)
+
+// RenderWebhooks writes an admission webhook per resource, which validates the local
+// leafrefs against the resource and the external leafrefs against the other resources
+// of the network node in the cluster
+func (g *Generator) RenderWebhooks() error {
+	dir := filepath.Join(g.Config.OutputDir, "webhooks", g.Config.Prefix)
+	if err := g.output.MkdirAll(dir); err != nil {
+		return err
+	}
+
+	kinds := make([]string, 0, len(g.Resources))
+	for _, r := range g.Resources {
+		fileName := filepath.Join(dir, g.Config.Prefix+"-"+strcase.KebabCase(r.GetAbsoluteName())+"_webhook.go")
+		if err := g.WriteWebhook(fileName, r); err != nil {
+			g.log.Debug("Write webhook error", "error", err)
+			return err
+		}
+		kinds = append(kinds, g.kind(r))
+	}
+
+	f := g.createGoFile(filepath.Join(dir, "setup.go"), nil)
+	defer f.Close()
+	s := struct {
+		Package       string
+		ApiGroup      string
+		Version       string
+		ApiImportPath string
+		Kinds         []string
+	}{
+		Package:       g.Config.Prefix,
+		ApiGroup:      g.Config.ApiGroup,
+		Version:       g.Config.Version,
+		ApiImportPath: g.apiImportPath(),
+		Kinds:         kinds,
+	}
+	if err := g.executeTemplate(f, "webhookSetup"+".tmpl", s); err != nil {
+		g.log.Debug("Write webhook setup error", "error", err)
+		return err
+	}
+	return f.Close()
+}
+
+// WriteWebhook
+func (g *Generator) WriteWebhook(fileName string, r *resource.Resource) error {
+	f := g.createGoFile(fileName, r)
+	defer f.Close()
+
+	kind := g.kind(r)
+	s := struct {
+		Package       string
+		ApiGroup      string
+		Version       string
+		ApiImportPath string
+		Kind          string
+		Plural        string
+		ResourceName  string
+		RootElement   string
+		RootKey       bool
+		PathElems     []*ControllerPathElem
+	}{
+		Package:       g.Config.Prefix,
+		ApiGroup:      g.Config.ApiGroup,
+		Version:       g.Config.Version,
+		ApiImportPath: g.apiImportPath(),
+		Kind:          kind,
+		Plural:        kindPlural(kind),
+		ResourceName:  r.GetResourceNameWithPrefix(""),
+		RootElement:   r.ResourceLastElement(),
+		RootKey:       r.RootContainerEntry != nil && r.RootContainerEntry.Key != "",
+		PathElems:     g.controllerPathElems(r, r),
+	}
+	if err := g.executeTemplate(f, "webhook"+".tmpl", s); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// kindPlural returns the plural resource name of the kind, which is pluralized in the
+// same way as controller-gen does for the crds, rbac rules and webhooks
+func kindPlural(kind string) string {
+	return strings.ToLower(flect.Pluralize(kind))
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package generator
 
 import (
+	"path/filepath"
+
 	"github.com/netw-device-driver/ndd-grpc/config/configpb"
 	"github.com/stoewer/go-strcase"
 	"github.com/yndd/ndd-yang/pkg/container"
@@ -24,13 +26,18 @@ import (
 	"github.com/yndd/ndd-yang/pkg/resource"
 )
 
-func (g *Generator) Render() error {
+// renderK8s writes the kubebuilder api types of the resources, together with the
+// controllers and webhooks of the provider
+func (g *Generator) renderK8s() error {
+	// Render the files shared by all resources of the api package
+	if err := g.RenderPackage(); err != nil {
+		return err
+	}
 	// Render the data
 	for _, r := range g.Resources {
 		r.AssignFileName(g.Config.Prefix, "_types.go")
-		if err := r.CreateFile(g.Config.OutputDir, "api", g.Config.Version); err != nil {
-			return err
-		}
+		f := g.createGoFile(filepath.Join(g.Config.OutputDir, "api", g.Config.Version, filepath.Base(r.FileName)), r)
+		g.resourceFiles[r] = f
 		if err := g.WriteResourceHeader(r); err != nil {
 			g.log.Debug("Write resource header error", "error", err)
 			return err
@@ -54,6 +61,16 @@ func (g *Generator) Render() error {
 			*/
 		}
 
+		// write the containers of the state tree, which are used in the observation
+		if st, ok := g.States[r]; ok {
+			for _, c := range st.ContainerList {
+				if err := g.WriteResourceContainers(r, c); err != nil {
+					g.log.Debug("Write resource state container error", "error", err)
+					return err
+				}
+			}
+		}
+
 		if err := g.WriteResourceEnd(r); err != nil {
 			g.log.Debug("Write resource end error", "error", err)
 			return err
@@ -69,7 +86,53 @@ func (g *Generator) Render() error {
 			return err
 		}
 
-		if err := r.CloseFile(); err != nil {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	if err := g.RenderDeepCopy(); err != nil {
+		return err
+	}
+	if err := g.RenderEnums(); err != nil {
+		return err
+	}
+	if err := g.RenderValidation(); err != nil {
+		return err
+	}
+	if err := g.RenderManaged(); err != nil {
+		return err
+	}
+	if err := g.RenderControllers(); err != nil {
+		return err
+	}
+	if err := g.RenderWebhooks(); err != nil {
+		return err
+	}
+	return g.RenderValidationReport()
+}
+
+// RenderPackage writes the scaffolding files of the api package, which define
+// the GroupVersion, SchemeBuilder and LeafRef used by the resource types
+func (g *Generator) RenderPackage() error {
+	s := struct {
+		Version  string
+		ApiGroup string
+	}{
+		Version:  g.Config.Version,
+		ApiGroup: g.Config.ApiGroup,
+	}
+
+	for fileName, tmplName := range map[string]string{
+		"groupversion_info.go": "groupVersionInfo",
+		"leafref.go":           "leafRef",
+	} {
+		f := g.createGoFile(filepath.Join(g.Config.OutputDir, "api", g.Config.Version, fileName), nil)
+		if err := g.executeTemplate(f, tmplName+".tmpl", s); err != nil {
+			g.log.Debug("Write package file error", "file", fileName, "error", err)
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
 			return err
 		}
 	}
@@ -83,6 +146,7 @@ func (g *Generator) WriteResourceHeader(r *resource.Resource) error {
 		ApiGroup               string
 		ResourceLastElement    string
 		ResourceNameWithPrefix string
+		HasLeafRefs            bool
 		ResourceTest1          *configpb.Path
 		ResourceTest2          *configpb.Path
 		ResourceTest3          *configpb.Path
@@ -90,10 +154,11 @@ func (g *Generator) WriteResourceHeader(r *resource.Resource) error {
 		Version:                g.Config.Version,
 		ApiGroup:               g.Config.ApiGroup,
 		ResourceLastElement:    strcase.LowerCamelCase(r.ResourceLastElement()),
-		ResourceNameWithPrefix: r.GetResourceNameWithPrefix(g.Config.Prefix),
+		ResourceNameWithPrefix: g.kind(r),
+		HasLeafRefs:            len(r.LocalLeafRefs) > 0 || len(r.ExternalLeafRefs) > 0,
 	}
 
-	if err := g.Template.ExecuteTemplate(r.ResFile, "resourceHeader"+".tmpl", s); err != nil {
+	if err := g.executeTemplate(g.resourceFiles[r], "resourceHeader"+".tmpl", s); err != nil {
 		return err
 	}
 	return nil
@@ -102,37 +167,50 @@ func (g *Generator) WriteResourceHeader(r *resource.Resource) error {
 // WriteResourceContainers
 func (g *Generator) WriteResourceContainers(r *resource.Resource, c *container.Container) error {
 	s := struct {
-		Name    string
-		Entries []*container.Entry
+		Name     string
+		TypeName string
+		Comment  []string
+		Entries  []*ContainerEntry
+		Rules    []*Rule
 	}{
-		Name:    c.GetFullName(),
-		Entries: c.Entries,
+		Name:     c.GetFullName(),
+		TypeName: g.goTypeName(c),
+		Comment:  commentLines(g.containerDescription(c)),
+		Entries:  g.containerEntries(c),
+		Rules:    g.Rules[c],
 	}
 
-	if err := g.Template.ExecuteTemplate(r.ResFile, "resourceContainer"+".tmpl", s); err != nil {
+	if err := g.executeTemplate(g.resourceFiles[r], "resourceContainer"+".tmpl", s); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (g *Generator) WriteResourceEnd(r *resource.Resource) error {
-
-	r.GetHierarchicalElements()
-
 	s := struct {
 		Prefix                 string
 		ResourceLastElement    string
 		ResourceName           string
 		ResourceNameWithPrefix string
-		HElements              []*resource.HeInfo
+		StateRoot              string
+		HierarchyKeys          []*HierarchyKey
+		PrintColumns           []CrdPrinterColumn
+		Scope                  string
+		Categories             []string
+		ShortNames             []string
 	}{
 		Prefix:                 g.Config.Prefix,
-		ResourceLastElement:    strcase.UpperCamelCase(r.ResourceLastElement()),
+		ResourceLastElement:    g.rootTypeName(r),
 		ResourceName:           r.GetResourceNameWithPrefix(""),
-		ResourceNameWithPrefix: r.GetResourceNameWithPrefix(g.Config.Prefix),
-		HElements:              r.GetHierarchicalElements(),
+		ResourceNameWithPrefix: g.kind(r),
+		StateRoot:              g.stateRoot(r),
+		HierarchyKeys:          g.hierarchyKeys(r),
+		PrintColumns:           g.printColumns(r),
+		Scope:                  g.scope(r),
+		Categories:             g.categories(r),
+		ShortNames:             g.shortNames(r),
 	}
-	if err := g.Template.ExecuteTemplate(r.ResFile, "resourceEnd"+".tmpl", s); err != nil {
+	if err := g.executeTemplate(g.resourceFiles[r], "resourceEnd"+".tmpl", s); err != nil {
 		return err
 	}
 	return nil
@@ -149,7 +227,7 @@ func (g *Generator) WriteResourceLocalLeafRef(r *resource.Resource) error {
 		LeafRefs:     r.LocalLeafRefs,
 	}
 	//g.log.Debug("local leafrefs", "local leafref", r.LocalLeafRefs)
-	if err := g.Template.ExecuteTemplate(r.ResFile, "resourceLeafRef"+".tmpl", s); err != nil {
+	if err := g.executeTemplate(g.resourceFiles[r], "resourceLeafRef"+".tmpl", s); err != nil {
 		return err
 	}
 	return nil
@@ -166,7 +244,7 @@ func (g *Generator) WriteResourceExternalLeafRef(r *resource.Resource) error {
 		LeafRefs:     r.ExternalLeafRefs,
 	}
 	//g.log.Debug("External leafrefs", "external leafref", r.LocalLeafRefs)
-	if err := g.Template.ExecuteTemplate(r.ResFile, "resourceLeafRef"+".tmpl", s); err != nil {
+	if err := g.executeTemplate(g.resourceFiles[r], "resourceLeafRef"+".tmpl", s); err != nil {
 		return err
 	}
 	return nil
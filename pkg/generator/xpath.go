@@ -0,0 +1,535 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pkg/errors"
+	"github.com/stoewer/go-strcase"
+	"github.com/yndd/ndd-yang/pkg/container"
+)
+
+const (
+	errXPathSyntax         = "invalid xpath expression"
+	errXPathAbsolutePath   = "absolute paths reference data outside of the resource"
+	errXPathPredicate      = "predicates are not supported"
+	errXPathFunction       = "function is not supported"
+	errXPathOperator       = "operator is not supported"
+	errXPathLeavesScope    = "path leaves the generated type of the rule"
+	errXPathUnknownNode    = "path references a node that is not part of the resource"
+	errXPathCrossesList    = "path crosses a list or leaf-list"
+	errXPathTypeMismatch   = "operands have different types"
+	errXPathNotComparable  = "operand cannot be compared"
+	errXPathNotBoolean     = "operand is not a boolean"
+	errXPathRelationalType = "relational operators are only supported on numbers"
+)
+
+// the kinds of the translated operands
+const (
+	celKindBool   = "bool"
+	celKindInt    = "int"
+	celKindString = "string"
+)
+
+// celReservedWords are escaped when used as a property name in a kubernetes CEL rule
+var celReservedWords = map[string]bool{
+	"true": true, "false": true, "null": true, "in": true, "as": true, "break": true,
+	"const": true, "continue": true, "else": true, "for": true, "function": true,
+	"if": true, "import": true, "let": true, "loop": true, "package": true,
+	"namespace": true, "return": true, "var": true, "void": true, "while": true,
+}
+
+// celOperand is a translated operand of an xpath expression
+type celOperand struct {
+	cel   string      // the CEL expression of the operand
+	kind  string      // the kind of the value of the operand
+	guard string      // the CEL expression which checks that a path exists, empty for literals
+	path  bool        // true when the operand is a path
+	yang  *yang.Entry // the yang entry of the leaf a path references
+}
+
+// xpathTranslator translates a yang xpath expression into a CEL rule on the
+// generated type of the scope container
+type xpathTranslator struct {
+	g      *Generator
+	scope  *container.Container
+	node   []string // the path from the scope to the context node of the expression
+	tokens []string
+	pos    int
+}
+
+// translateXPath translates the xpath expression into a CEL rule that is evaluated
+// on the generated type of container scope, where node is the path from the scope
+// to the context node of the expression
+func (g *Generator) translateXPath(scope *container.Container, node []string, xpath string) (string, error) {
+	tokens, err := tokenizeXPath(xpath)
+	if err != nil {
+		return "", err
+	}
+	t := &xpathTranslator{
+		g:      g,
+		scope:  scope,
+		node:   node,
+		tokens: tokens,
+	}
+	o, err := t.orExpr()
+	if err != nil {
+		return "", err
+	}
+	if t.pos != len(t.tokens) {
+		return "", errors.New(errXPathSyntax)
+	}
+	return t.boolean(o)
+}
+
+// tokenizeXPath splits the xpath expression in literals, operators, names and paths
+func tokenizeXPath(xpath string) ([]string, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(xpath); {
+		c := xpath[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			j := strings.IndexByte(xpath[i+1:], c)
+			if j < 0 {
+				return nil, errors.New(errXPathSyntax)
+			}
+			tokens = append(tokens, xpath[i:i+j+2])
+			i += j + 2
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '!' || c == '<' || c == '>':
+			if i+1 < len(xpath) && xpath[i+1] == '=' {
+				tokens = append(tokens, xpath[i:i+2])
+				i += 2
+				continue
+			}
+			tokens = append(tokens, string(c))
+			i++
+		case c == '[' || c == ']':
+			return nil, errors.New(errXPathPredicate)
+		case isXPathNameChar(c):
+			j := i
+			for j < len(xpath) && isXPathNameChar(xpath[j]) {
+				j++
+			}
+			tokens = append(tokens, xpath[i:j])
+			i = j
+		default:
+			return nil, errors.New(errXPathOperator)
+		}
+	}
+	return tokens, nil
+}
+
+// isXPathNameChar returns true for the characters of names, numbers and location paths
+func isXPathNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.' || c == ':' || c == '/' || c == '*'
+}
+
+func (t *xpathTranslator) peek() string {
+	if t.pos < len(t.tokens) {
+		return t.tokens[t.pos]
+	}
+	return ""
+}
+
+func (t *xpathTranslator) next() string {
+	tok := t.peek()
+	t.pos++
+	return tok
+}
+
+func (t *xpathTranslator) expect(tok string) error {
+	if t.next() != tok {
+		return errors.New(errXPathSyntax)
+	}
+	return nil
+}
+
+// orExpr = andExpr ("or" andExpr)*
+func (t *xpathTranslator) orExpr() (*celOperand, error) {
+	return t.logicalExpr("or", "||", t.andExpr)
+}
+
+// andExpr = cmpExpr ("and" cmpExpr)*
+func (t *xpathTranslator) andExpr() (*celOperand, error) {
+	return t.logicalExpr("and", "&&", t.cmpExpr)
+}
+
+func (t *xpathTranslator) logicalExpr(op, celOp string, operand func() (*celOperand, error)) (*celOperand, error) {
+	o, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if t.peek() != op {
+		return o, nil
+	}
+	l, err := t.boolean(o)
+	if err != nil {
+		return nil, err
+	}
+	for t.peek() == op {
+		t.next()
+		o, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		r, err := t.boolean(o)
+		if err != nil {
+			return nil, err
+		}
+		l = "(" + l + " " + celOp + " " + r + ")"
+	}
+	return &celOperand{cel: l, kind: celKindBool}, nil
+}
+
+// cmpExpr = unaryExpr (("=" | "!=" | "<" | "<=" | ">" | ">=") unaryExpr)?
+func (t *xpathTranslator) cmpExpr() (*celOperand, error) {
+	l, err := t.unaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	switch op := t.peek(); op {
+	case "=", "!=", "<", "<=", ">", ">=":
+		t.next()
+		r, err := t.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		return t.compare(l, op, r)
+	}
+	return l, nil
+}
+
+// unaryExpr = "(" orExpr ")" | function "(" args ")" | literal | number | path
+func (t *xpathTranslator) unaryExpr() (*celOperand, error) {
+	tok := t.next()
+	switch {
+	case tok == "":
+		return nil, errors.New(errXPathSyntax)
+	case tok == "(":
+		o, err := t.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect(")"); err != nil {
+			return nil, err
+		}
+		if o.path {
+			return o, nil
+		}
+		return &celOperand{cel: "(" + o.cel + ")", kind: o.kind}, nil
+	case tok[0] == '\'' || tok[0] == '"':
+		return &celOperand{cel: tok[1 : len(tok)-1], kind: celKindString}, nil
+	case tok[0] >= '0' && tok[0] <= '9':
+		if _, err := strconv.ParseInt(tok, 10, 64); err != nil {
+			return nil, errors.New(errXPathNotComparable)
+		}
+		return &celOperand{cel: tok, kind: celKindInt}, nil
+	case t.peek() == "(":
+		return t.function(tok)
+	}
+	return t.path(t.node, tok)
+}
+
+// function translates the supported xpath functions
+func (t *xpathTranslator) function(name string) (*celOperand, error) {
+	t.next()
+	switch name {
+	case "true", "false":
+		if err := t.expect(")"); err != nil {
+			return nil, err
+		}
+		return &celOperand{cel: name, kind: celKindBool}, nil
+	case "current":
+		if err := t.expect(")"); err != nil {
+			return nil, err
+		}
+		// a location path can follow the context node
+		if tok := t.peek(); strings.HasPrefix(tok, "/") {
+			t.next()
+			return t.path(t.node, strings.TrimPrefix(tok, "/"))
+		}
+		return t.path(t.node, ".")
+	case "not":
+		o, err := t.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect(")"); err != nil {
+			return nil, err
+		}
+		b, err := t.boolean(o)
+		if err != nil {
+			return nil, err
+		}
+		return &celOperand{cel: "!" + celParen(b), kind: celKindBool}, nil
+	case "starts-with", "contains":
+		s, err := t.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect(","); err != nil {
+			return nil, err
+		}
+		sub, err := t.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect(")"); err != nil {
+			return nil, err
+		}
+		if !s.path || s.kind != celKindString || sub.path || sub.kind != celKindString {
+			return nil, errors.New(errXPathNotComparable)
+		}
+		method := "startsWith"
+		if name == "contains" {
+			method = "contains"
+		}
+		return &celOperand{
+			cel:  "(" + s.guard + " && " + s.cel + "." + method + "(" + celQuote(sub.cel) + "))",
+			kind: celKindBool,
+		}, nil
+	}
+	return nil, errors.Errorf("%s: %s()", errXPathFunction, name)
+}
+
+// path translates a relative location path, starting from the node path from the scope
+func (t *xpathTranslator) path(node []string, p string) (*celOperand, error) {
+	if strings.HasPrefix(p, "/") {
+		return nil, errors.New(errXPathAbsolutePath)
+	}
+	segments := append([]string{}, node...)
+	for _, step := range strings.Split(p, "/") {
+		switch step {
+		case "", ".":
+		case "..":
+			if len(segments) == 0 {
+				return nil, errors.New(errXPathLeavesScope)
+			}
+			segments = segments[:len(segments)-1]
+		default:
+			if strings.Contains(step, "*") {
+				return nil, errors.New(errXPathOperator)
+			}
+			// the module prefixes are not part of the generated types
+			if i := strings.LastIndexByte(step, ':'); i >= 0 {
+				step = step[i+1:]
+			}
+			segments = append(segments, step)
+		}
+	}
+	if len(segments) == 0 {
+		// the scope itself always exists
+		return &celOperand{cel: "self", guard: "true", path: true}, nil
+	}
+
+	o := &celOperand{cel: "self", path: true}
+	guards := make([]string, 0, len(segments))
+	c := t.scope
+	for i, s := range segments {
+		if c == nil {
+			return nil, errors.New(errXPathCrossesList)
+		}
+		var ce *container.Entry
+		for _, e := range c.Entries {
+			if e.Name == s {
+				ce = e
+				break
+			}
+		}
+		if ce == nil {
+			return nil, errors.New(errXPathUnknownNode)
+		}
+		o.cel += "." + celFieldName(ce.Name)
+		guards = append(guards, "has("+o.cel+")")
+		switch {
+		case ce.Next != nil && ce.Key == "":
+			c = ce.Next
+		case i == len(segments)-1:
+			c = nil
+			if ce.Next == nil && t.g.LeafLists[ce] == nil {
+				// a leaf, which can be compared
+				o.kind = celKindOf(ce.Type)
+				o.yang = t.g.yangEntries[ce]
+			}
+		default:
+			return nil, errors.New(errXPathCrossesList)
+		}
+	}
+	o.guard = strings.Join(guards, " && ")
+	return o, nil
+}
+
+// compare translates the comparison of two operands
+func (t *xpathTranslator) compare(l *celOperand, op string, r *celOperand) (*celOperand, error) {
+	if !l.path && r.path {
+		// keep the path on the left side
+		l, r = r, l
+		switch op {
+		case "<":
+			op = ">"
+		case "<=":
+			op = ">="
+		case ">":
+			op = "<"
+		case ">=":
+			op = "<="
+		}
+	}
+	if !l.path || l.kind == "" {
+		return nil, errors.New(errXPathNotComparable)
+	}
+	celOp := op
+	if op == "=" {
+		celOp = "=="
+	}
+
+	var value string
+	if r.path {
+		if r.kind == "" {
+			return nil, errors.New(errXPathNotComparable)
+		}
+		if r.kind != l.kind {
+			return nil, errors.New(errXPathTypeMismatch)
+		}
+		value = r.cel
+	} else {
+		var err error
+		if value, err = literalOfKind(l, r); err != nil {
+			return nil, err
+		}
+	}
+	if op != "=" && op != "!=" && l.kind != celKindInt {
+		return nil, errors.New(errXPathRelationalType)
+	}
+
+	guard := l.guard
+	if r.path {
+		guard += " && " + r.guard
+	}
+	return &celOperand{cel: "(" + guard + " && " + l.cel + " " + celOp + " " + value + ")", kind: celKindBool}, nil
+}
+
+// literalOfKind returns the CEL literal of the xpath literal r, converted to the kind of path l
+func literalOfKind(l, r *celOperand) (string, error) {
+	switch l.kind {
+	case celKindString:
+		if r.kind != celKindString {
+			return "", errors.New(errXPathTypeMismatch)
+		}
+		v := r.cel
+		// identities are generated without their module prefix
+		if l.yang != nil && l.yang.Type != nil && l.yang.Type.Kind == yang.Yidentityref {
+			if i := strings.LastIndexByte(v, ':'); i >= 0 {
+				v = v[i+1:]
+			}
+		}
+		return celQuote(v), nil
+	case celKindInt:
+		if _, err := strconv.ParseInt(r.cel, 10, 64); err != nil {
+			return "", errors.New(errXPathTypeMismatch)
+		}
+		return r.cel, nil
+	case celKindBool:
+		if r.cel != "true" && r.cel != "false" {
+			return "", errors.New(errXPathTypeMismatch)
+		}
+		return r.cel, nil
+	}
+	return "", errors.New(errXPathTypeMismatch)
+}
+
+// boolean returns the CEL expression of an operand that is used as a boolean, a
+// path is true when it exists
+func (t *xpathTranslator) boolean(o *celOperand) (string, error) {
+	switch {
+	case o.path:
+		// the guard of a path with multiple segments is a conjunction
+		return celParen(o.guard), nil
+	case o.kind == celKindBool:
+		return o.cel, nil
+	}
+	return "", errors.New(errXPathNotBoolean)
+}
+
+// celParen returns the CEL expression in parentheses, unless it is a single term or
+// already enclosed in a single pair of parentheses
+func celParen(s string) string {
+	if !strings.ContainsAny(s, " \t") {
+		return s
+	}
+	if strings.HasPrefix(s, "(") {
+		depth, quoted := 0, false
+		for i := 0; i < len(s); i++ {
+			switch {
+			case quoted && s[i] == '\\':
+				i++
+			case s[i] == '\'':
+				quoted = !quoted
+			case !quoted && s[i] == '(':
+				depth++
+			case !quoted && s[i] == ')':
+				depth--
+				if depth == 0 {
+					if i == len(s)-1 {
+						return s
+					}
+					return "(" + s + ")"
+				}
+			}
+		}
+	}
+	return "(" + s + ")"
+}
+
+// celKindOf returns the CEL kind of the go type of a container entry
+func celKindOf(t string) string {
+	switch t {
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		return celKindInt
+	case "bool":
+		return celKindBool
+	}
+	return celKindString
+}
+
+// celFieldName returns the name of a property of the generated type in a kubernetes
+// CEL rule, which escapes the characters that are not allowed in CEL identifiers
+func celFieldName(name string) string {
+	n := strcase.KebabCase(name)
+	if celReservedWords[n] {
+		return "__" + n + "__"
+	}
+	n = strings.ReplaceAll(n, "__", "__underscores__")
+	n = strings.ReplaceAll(n, ".", "__dot__")
+	n = strings.ReplaceAll(n, "-", "__dash__")
+	return strings.ReplaceAll(n, "/", "__slash__")
+}
+
+// celQuote returns the CEL string literal of s
+func celQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/yndd/ndd-yang/pkg/container"
+)
+
+// newXPathTestScope returns an interface container with a mtu leaf, an ethernet
+// container and a subinterface list
+func newXPathTestScope() *container.Container {
+	c := container.NewContainer("interface", nil)
+	ethernet := container.NewContainer("ethernet", c)
+	subinterface := container.NewContainer("subinterface", c)
+
+	addEntry := func(c *container.Container, name, typ string, next *container.Container) *container.Entry {
+		e := container.NewEntry(name)
+		e.Type = typ
+		e.Next = next
+		e.Prev = c
+		c.Entries = append(c.Entries, e)
+		return e
+	}
+	addEntry(c, "name", "string", nil)
+	addEntry(c, "mtu", "uint16", nil)
+	addEntry(c, "ethernet", "InterfaceEthernet", ethernet)
+	addEntry(c, "subinterface", "InterfaceSubinterface", subinterface).Key = "index"
+	addEntry(ethernet, "aggregate-id", "string", nil)
+	addEntry(ethernet, "duplex-mode", "string", nil)
+	addEntry(subinterface, "index", "uint32", nil)
+	return c
+}
+
+func TestTranslateXPath(t *testing.T) {
+	cases := map[string]struct {
+		node  []string
+		xpath string
+		want  string
+		err   string
+	}{
+		"Path": {
+			xpath: "mtu",
+			want:  "has(self.mtu)",
+		},
+		"NestedPath": {
+			xpath: "ethernet/duplex-mode",
+			want:  "(has(self.ethernet) && has(self.ethernet.duplex__dash__mode))",
+		},
+		"NotPath": {
+			xpath: "not(mtu)",
+			want:  "!has(self.mtu)",
+		},
+		"NotNestedPath": {
+			node:  []string{"ethernet", "aggregate-id"},
+			xpath: "not(../../ethernet/duplex-mode)",
+			want:  "!(has(self.ethernet) && has(self.ethernet.duplex__dash__mode))",
+		},
+		"NotComparison": {
+			xpath: "not(mtu = 1500)",
+			want:  "!(has(self.mtu) && self.mtu == 1500)",
+		},
+		"AndNestedPaths": {
+			xpath: "ethernet/duplex-mode and ethernet/aggregate-id",
+			want:  "((has(self.ethernet) && has(self.ethernet.duplex__dash__mode)) && (has(self.ethernet) && has(self.ethernet.aggregate__dash__id)))",
+		},
+		"OrNestedPathComparison": {
+			xpath: "ethernet/duplex-mode or mtu >= 1500",
+			want:  "((has(self.ethernet) && has(self.ethernet.duplex__dash__mode)) || (has(self.mtu) && self.mtu >= 1500))",
+		},
+		"NotAnd": {
+			xpath: "not(ethernet/duplex-mode and mtu)",
+			want:  "!((has(self.ethernet) && has(self.ethernet.duplex__dash__mode)) && has(self.mtu))",
+		},
+		"NotOrNested": {
+			node:  []string{"ethernet"},
+			xpath: "not(duplex-mode or ../mtu) or not(aggregate-id)",
+			want:  "(!((has(self.ethernet) && has(self.ethernet.duplex__dash__mode)) || has(self.mtu)) || !(has(self.ethernet) && has(self.ethernet.aggregate__dash__id)))",
+		},
+		"StringComparison": {
+			node:  []string{"ethernet", "duplex-mode"},
+			xpath: ". = 'full (auto)'",
+			want:  "(has(self.ethernet) && has(self.ethernet.duplex__dash__mode) && self.ethernet.duplex__dash__mode == 'full (auto)')",
+		},
+		"AbsolutePath": {
+			xpath: "/interface/mtu",
+			err:   errXPathAbsolutePath,
+		},
+		"Predicate": {
+			xpath: "subinterface[index=0]",
+			err:   errXPathPredicate,
+		},
+		"CrossesList": {
+			xpath: "subinterface/index",
+			err:   errXPathCrossesList,
+		},
+		"UnknownNode": {
+			xpath: "ethernet/speed",
+			err:   errXPathUnknownNode,
+		},
+		"LeavesScope": {
+			xpath: "../mtu",
+			err:   errXPathLeavesScope,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := &Generator{}
+			got, err := g.translateXPath(newXPathTestScope(), tc.node, tc.xpath)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("translateXPath(%q): want error %q, got %q, %v", tc.xpath, tc.err, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("translateXPath(%q): unexpected error: %v", tc.xpath, err)
+			}
+			if got != tc.want {
+				t.Errorf("translateXPath(%q):\nwant: %s\ngot:  %s", tc.xpath, tc.want, got)
+			}
+		})
+	}
+}
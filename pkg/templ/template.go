@@ -30,12 +30,22 @@ import (
 	"text/template"
 
 	"github.com/Masterminds/sprig"
+	"github.com/netw-device-driver/ndd-ygen/templates"
 	"github.com/stoewer/go-strcase"
 )
 
-func ParseTemplates(path string) (*template.Template, error) {
-	templ := template.New("ndd").Funcs(templateHelperFunctions).Funcs(sprig.TxtFuncMap())
-	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+// ParseTemplates parses the default templates that are embedded in the binary, the
+// templates in dir replace the default templates with the same name and add the others.
+// Only the default templates are parsed when dir is empty.
+func ParseTemplates(dir string) (*template.Template, error) {
+	templ, err := template.New("ndd").Funcs(templateHelperFunctions).Funcs(sprig.TxtFuncMap()).ParseFS(templates.FS, "*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return templ, nil
+	}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if strings.HasSuffix(path, ".tmpl") {
 			_, err = templ.ParseFiles(path)
 			if err != nil {